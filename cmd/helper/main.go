@@ -17,8 +17,10 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/buildpacks/libcnb"
 	"github.com/paketo-buildpacks/libpak/bard"
 	"github.com/paketo-buildpacks/libpak/sherpa"
 
@@ -27,8 +29,24 @@ import (
 
 func main() {
 	sherpa.Execute(func() error {
+		bindings, err := libcnb.NewBindingsForLaunch()
+		if err != nil {
+			return fmt.Errorf("unable to read bindings from environment\n%w", err)
+		}
+
+		logger := bard.NewLogger(os.Stdout)
+
 		return sherpa.Helpers(map[string]sherpa.ExecD{
-			"access-logging-support": helper.AccessLoggingSupport{Logger: bard.NewLogger(os.Stdout)},
+			"app-cds":                helper.AppCDS{Logger: logger},
+			"access-logging-support": helper.AccessLoggingSupport{Logger: logger},
+			"binding-placeholders":   helper.BindingPlaceholders{Bindings: bindings, Logger: logger},
+			"connector-sizing":       helper.ConnectorSizing{Logger: logger},
+			"manager-users":          helper.ManagerUsers{Bindings: bindings, Logger: logger},
+			"opentelemetry":          helper.OpenTelemetry{Logger: logger},
+			"placeholder-audit":      helper.PlaceholderAudit{Logger: logger},
+			"realm":                  helper.Realm{Bindings: bindings, Logger: logger},
+			"remote-ip":              helper.RemoteIP{Logger: logger},
+			"virtual-threads":        helper.VirtualThreads{Logger: logger},
 		})
 	})
 }
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// AppCDS uses the AppCDS archive created by the build's training run, $BPI_TOMCAT_APPCDS_ARCHIVE, by adding
+// -XX:SharedArchiveFile to $JAVA_TOOL_OPTIONS.  An archive can only be used by the JRE that created it, so the helper
+// warns and skips it when the JRE in $JAVA_HOME is not $BPI_TOMCAT_APPCDS_JAVA_VERSION.
+type AppCDS struct {
+	Logger bard.Logger
+}
+
+func (a AppCDS) Execute() (map[string]string, error) {
+	archive, ok := os.LookupEnv("BPI_TOMCAT_APPCDS_ARCHIVE")
+	if !ok {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(archive); err != nil {
+		a.Logger.Infof("WARNING: not using AppCDS archive, unable to find %s", archive)
+		return nil, nil
+	}
+
+	expected := os.Getenv("BPI_TOMCAT_APPCDS_JAVA_VERSION")
+	actual, err := JavaRuntimeVersion()
+	if err != nil {
+		a.Logger.Infof("WARNING: not using AppCDS archive, %s", err)
+		return nil, nil
+	}
+	if actual != expected {
+		a.Logger.Infof("WARNING: not using AppCDS archive, it was created by Java %s and the JRE is Java %s", expected, actual)
+		return nil, nil
+	}
+
+	a.Logger.Infof("Using AppCDS archive %s", archive)
+
+	var values []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		values = append(values, s)
+	}
+	values = append(values, fmt.Sprintf("-XX:SharedArchiveFile=%s", archive))
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testAppCDS(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		a        = helper.AppCDS{}
+		archive  string
+		javaHome string
+		layer    string
+	)
+
+	it.Before(func() {
+		var err error
+		layer, err = os.MkdirTemp("", "app-cds")
+		Expect(err).NotTo(HaveOccurred())
+
+		archive = filepath.Join(layer, "tomcat.jsa")
+		Expect(os.WriteFile(archive, []byte{}, 0644)).To(Succeed())
+
+		javaHome, err = os.MkdirTemp("", "java-home")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(javaHome, "release"),
+			[]byte("JAVA_RUNTIME_VERSION=\"21.0.4+9-LTS\"\nJAVA_VERSION=\"21.0.4\"\n"), 0644)).To(Succeed())
+		t.Setenv("JAVA_HOME", javaHome)
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layer)).To(Succeed())
+		Expect(os.RemoveAll(javaHome)).To(Succeed())
+	})
+
+	it("does nothing without an archive", func() {
+		Expect(a.Execute()).To(BeNil())
+	})
+
+	context("$BPI_TOMCAT_APPCDS_ARCHIVE", func() {
+		it.Before(func() {
+			t.Setenv("BPI_TOMCAT_APPCDS_ARCHIVE", archive)
+			t.Setenv("BPI_TOMCAT_APPCDS_JAVA_VERSION", "21.0.4+9-LTS")
+		})
+
+		it("uses archive", func() {
+			Expect(a.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-XX:SharedArchiveFile=" + archive,
+			}))
+		})
+
+		it("appends to $JAVA_TOOL_OPTIONS", func() {
+			t.Setenv("JAVA_TOOL_OPTIONS", "-Xss1M")
+
+			Expect(a.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-Xss1M -XX:SharedArchiveFile=" + archive,
+			}))
+		})
+
+		it("does not use archive created by a different JRE", func() {
+			t.Setenv("BPI_TOMCAT_APPCDS_JAVA_VERSION", "21.0.3+9-LTS")
+
+			Expect(a.Execute()).To(BeNil())
+		})
+
+		it("does not use archive if the JRE is unknown", func() {
+			Expect(os.Remove(filepath.Join(javaHome, "release"))).To(Succeed())
+
+			Expect(a.Execute()).To(BeNil())
+		})
+
+		it("does not use missing archive", func() {
+			Expect(os.Remove(archive)).To(Succeed())
+
+			Expect(a.Execute()).To(BeNil())
+		})
+	})
+}
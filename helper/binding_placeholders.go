@@ -0,0 +1,160 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// BindingPlaceholderPrefix is the prefix of ${binding:<binding-name>:<key>} placeholders.
+const BindingPlaceholderPrefix = "binding:"
+
+// BindingPlaceholders resolves the ${binding:<binding-name>:<key>} placeholders in the configuration of $CATALINA_BASE
+// from bindings.  Tomcat sets the entries of catalina.properties as system properties before it parses its
+// configuration, so the values are written, along with $CATALINA_BASE/conf/catalina.properties, to a catalina.properties
+// in $TMPDIR that only the user can read and Tomcat is pointed to it with -Dcatalina.config.  The values are never
+// exposed as environment variables or on the command line.  Placeholders without a binding or key are left for the
+// placeholder audit to report.
+type BindingPlaceholders struct {
+	Bindings libcnb.Bindings
+	Logger   bard.Logger
+}
+
+func (b BindingPlaceholders) Execute() (map[string]string, error) {
+	catalinaBase, ok := os.LookupEnv("CATALINA_BASE")
+	if !ok {
+		return nil, fmt.Errorf("$CATALINA_BASE must be set")
+	}
+
+	placeholders, err := FindPlaceholders(catalinaBase)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for _, p := range placeholders {
+		// ${name:-default} reads the system property name
+		name, _, _ := strings.Cut(p.Name, ":-")
+		if _, ok := values[name]; ok {
+			continue
+		}
+		if v, ok := b.resolve(name); ok {
+			values[name] = v
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	var names []string
+	for n := range values {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var s strings.Builder
+	file := filepath.Join(catalinaBase, "conf", "catalina.properties")
+	if in, err := os.ReadFile(file); err == nil {
+		s.Write(in)
+		if len(in) > 0 && in[len(in)-1] != '\n' {
+			s.WriteString("\n")
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	s.WriteString("\n# resolved from bindings by the binding-placeholders helper\n")
+	for _, n := range names {
+		s.WriteString(escapeProperty(n, true) + "=" + escapeProperty(values[n], false) + "\n")
+	}
+
+	file = filepath.Join(os.TempDir(), "tomcat-catalina.properties")
+	if err := os.WriteFile(file, []byte(s.String()), 0600); err != nil {
+		return nil, fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+	b.Logger.Infof("Tomcat placeholders resolved from bindings: %s", strings.Join(names, ", "))
+
+	var options []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		options = append(options, s)
+	}
+	options = append(options, fmt.Sprintf("-Dcatalina.config=file:%s", file))
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(options, " ")}, nil
+}
+
+// resolve returns the value of the key of the binding a ${binding:<binding-name>:<key>} placeholder refers to.
+func (b BindingPlaceholders) resolve(name string) (string, bool) {
+	if !strings.HasPrefix(name, BindingPlaceholderPrefix) {
+		return "", false
+	}
+
+	binding, key, ok := strings.Cut(strings.TrimPrefix(name, BindingPlaceholderPrefix), ":")
+	if !ok {
+		return "", false
+	}
+
+	for _, bd := range b.Bindings {
+		if bd.Name == binding {
+			v, ok := bd.Secret[key]
+			return strings.TrimRight(v, "\r\n"), ok
+		}
+	}
+
+	return "", false
+}
+
+// escapeProperty escapes s for use as the key, or the value, of an entry in a .properties file, which Tomcat reads as
+// ISO 8859-1.
+func escapeProperty(s string, key bool) string {
+	var b strings.Builder
+
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case strings.ContainsRune("=:#! ", r) && (key || i == 0):
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, u := range utf16.Encode([]rune{r}) {
+				_, _ = fmt.Fprintf(&b, `\u%04x`, u)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
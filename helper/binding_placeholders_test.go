@@ -0,0 +1,92 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testBindingPlaceholders(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		b            helper.BindingPlaceholders
+		catalinaBase string
+		tmp          string
+	)
+
+	it.Before(func() {
+		var err error
+		catalinaBase, err = os.MkdirTemp("", "binding-placeholders")
+		Expect(err).NotTo(HaveOccurred())
+		t.Setenv("CATALINA_BASE", catalinaBase)
+
+		tmp, err = os.MkdirTemp("", "binding-placeholders-tmp")
+		Expect(err).NotTo(HaveOccurred())
+		t.Setenv("TMPDIR", tmp)
+
+		Expect(os.MkdirAll(filepath.Join(catalinaBase, "conf"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(catalinaBase, "conf", "context.xml"), []byte(`<Context>
+    <!-- ${binding:db:commented} -->
+    <Resource name='jdbc/db' username='${binding:db:username}' password='${binding:db:password:-changeme}' url='${binding:db:url}'/>
+    <Environment name='port' value='${binding:other:port}'/>
+</Context>
+`), 0644)).To(Succeed())
+
+		b = helper.BindingPlaceholders{Bindings: libcnb.Bindings{
+			{Name: "db", Secret: map[string]string{"username": "tomcat\n", "password": "p=ss:wörd", "commented": "x"}},
+		}}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(catalinaBase)).To(Succeed())
+		Expect(os.RemoveAll(tmp)).To(Succeed())
+	})
+
+	it("writes the resolved placeholders to catalina.properties", func() {
+		Expect(os.WriteFile(filepath.Join(catalinaBase, "conf", "catalina.properties"), []byte("test.key=test-value"), 0644)).To(Succeed())
+		t.Setenv("JAVA_TOOL_OPTIONS", "-Dtest=value")
+
+		file := filepath.Join(tmp, "tomcat-catalina.properties")
+		Expect(b.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-Dtest=value -Dcatalina.config=file:" + file}))
+
+		Expect(os.ReadFile(file)).To(Equal([]byte(`test.key=test-value
+
+# resolved from bindings by the binding-placeholders helper
+binding\:db\:password=p=ss:w\u00f6rd
+binding\:db\:username=tomcat
+`)))
+		info, err := os.Stat(file)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	it("does nothing without binding placeholders", func() {
+		b.Bindings = nil
+
+		Expect(b.Execute()).To(BeNil())
+		Expect(filepath.Join(tmp, "tomcat-catalina.properties")).NotTo(BeAnExistingFile())
+	})
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	// DefaultCgroupRoot is where the cgroup filesystem is mounted.
+	DefaultCgroupRoot = "/sys/fs/cgroup"
+
+	// DefaultJVMThreadCount is the thread count the JVM memory calculator assumes when $BPL_JVM_THREAD_COUNT is not set.
+	DefaultJVMThreadCount = 250
+
+	// JVMThreadHeadroom is the number of threads, such as GC, compiler and Tomcat's own, reserved next to the
+	// connector's request processing threads.
+	JVMThreadHeadroom = 50
+
+	// MaxThreads is the largest maxThreads the helper configures, Tomcat's default.
+	MaxThreads = 200
+
+	// MinThreads is the smallest maxThreads the helper configures.
+	MinThreads = 10
+
+	// ThreadsPerCPU is the number of request processing threads per CPU.
+	ThreadsPerCPU = 50
+
+	// MemoryPerThread is the memory, in bytes, a request processing thread is budgeted: its 1 MiB stack is at most
+	// one eighth of the memory limit.
+	MemoryPerThread = 8 * 1024 * 1024
+)
+
+// ConnectorSizing sizes the thread pool and queues of the Connectors from the CPU and memory limits of the container's
+// cgroup when $BPL_TOMCAT_CONNECTOR_SIZING_ENABLED is true.  maxThreads is the smallest of ThreadsPerCPU per CPU, the
+// memory limit divided by MemoryPerThread and the thread count the JVM memory calculator accounts for, between
+// MinThreads and MaxThreads.  acceptCount is maxThreads and maxConnections is 20 times maxThreads.
+// $BPL_TOMCAT_MAX_THREADS, $BPL_TOMCAT_ACCEPT_COUNT and $BPL_TOMCAT_MAX_CONNECTIONS replace the computed values and
+// apply whether or not sizing is enabled.  The values are added to $JAVA_TOOL_OPTIONS as the tomcat.connector.*
+// system properties read by the placeholders the buildpack adds to $CATALINA_BASE/conf/server.xml, so the
+// configuration is not modified at launch.
+type ConnectorSizing struct {
+	CgroupRoot string
+	Logger     bard.Logger
+}
+
+// Limits are the CPU and memory limits of a cgroup.  Zero means unlimited.
+type Limits struct {
+	CPUs   float64
+	Memory int64
+}
+
+func (c ConnectorSizing) Execute() (map[string]string, error) {
+	enabled := sherpa.ResolveBool("BPL_TOMCAT_CONNECTOR_SIZING_ENABLED")
+
+	var (
+		limits Limits
+		size   int
+		err    error
+	)
+	if enabled {
+		root := c.CgroupRoot
+		if root == "" {
+			root = DefaultCgroupRoot
+		}
+
+		if limits, err = ReadLimits(root); err != nil {
+			return nil, fmt.Errorf("unable to read cgroup limits\n%w", err)
+		}
+
+		jvmThreadCount := DefaultJVMThreadCount
+		if s, ok := os.LookupEnv("BPL_JVM_THREAD_COUNT"); ok {
+			if jvmThreadCount, err = strconv.Atoi(strings.TrimSpace(s)); err != nil {
+				return nil, fmt.Errorf("unable to parse $BPL_JVM_THREAD_COUNT %q\n%w", s, err)
+			}
+		}
+
+		size = Size(limits, jvmThreadCount)
+	}
+
+	var properties []string
+	property := func(name string, value int, set bool) {
+		if enabled || set {
+			properties = append(properties, fmt.Sprintf("-Dtomcat.connector.%s=%d", name, value))
+		}
+	}
+
+	maxThreads, set, err := explicit("BPL_TOMCAT_MAX_THREADS", size)
+	if err != nil {
+		return nil, err
+	}
+	property("maxThreads", maxThreads, set)
+
+	acceptCount, set, err := explicit("BPL_TOMCAT_ACCEPT_COUNT", maxThreads)
+	if err != nil {
+		return nil, err
+	}
+	property("acceptCount", acceptCount, set)
+
+	maxConnections, set, err := explicit("BPL_TOMCAT_MAX_CONNECTIONS", maxThreads*20)
+	if err != nil {
+		return nil, err
+	}
+	property("maxConnections", maxConnections, set)
+
+	if len(properties) == 0 {
+		return nil, nil
+	}
+
+	if enabled {
+		c.Logger.Infof("Tomcat Connector sizing for %s CPUs and %s memory: %s",
+			formatCPUs(limits.CPUs), formatMemory(limits.Memory), strings.Join(properties, " "))
+	} else {
+		c.Logger.Infof("Tomcat Connector configuration: %s", strings.Join(properties, " "))
+	}
+
+	var values []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		values = append(values, s)
+	}
+	values = append(values, properties...)
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
+
+// explicit returns the value of the environment variable name and true, if set, or value and false.
+func explicit(name string, value int) (int, bool, error) {
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return value, false, nil
+	}
+
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to parse $%s %q\n%w", name, s, err)
+	}
+	return v, true, nil
+}
+
+// Size returns the maxThreads for limits, leaving JVMThreadHeadroom of the jvmThreadCount that the JVM memory
+// calculator accounts for.
+func Size(limits Limits, jvmThreadCount int) int {
+	cpus := limits.CPUs
+	if cpus == 0 {
+		cpus = float64(runtime.NumCPU())
+	}
+
+	threads := int(math.Ceil(cpus)) * ThreadsPerCPU
+	if limits.Memory > 0 && int(limits.Memory/MemoryPerThread) < threads {
+		threads = int(limits.Memory / MemoryPerThread)
+	}
+	if jvmThreadCount-JVMThreadHeadroom < threads {
+		threads = jvmThreadCount - JVMThreadHeadroom
+	}
+
+	if threads > MaxThreads {
+		threads = MaxThreads
+	}
+	if threads < MinThreads {
+		threads = MinThreads
+	}
+
+	return threads
+}
+
+// ReadLimits reads the CPU and memory limits from a cgroup v2 or, failing that, a cgroup v1 filesystem mounted at root.
+func ReadLimits(root string) (Limits, error) {
+	var limits Limits
+
+	if s, ok, err := readCgroupFile(filepath.Join(root, "cpu.max")); err != nil {
+		return Limits{}, err
+	} else if ok {
+		f := strings.Fields(s)
+		if len(f) == 2 && f[0] != "max" {
+			if limits.CPUs, err = ratio(f[0], f[1]); err != nil {
+				return Limits{}, fmt.Errorf("unable to parse cpu.max %q\n%w", s, err)
+			}
+		}
+	} else if quota, ok, err := readCgroupFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us")); err != nil {
+		return Limits{}, err
+	} else if ok && quota != "-1" {
+		period, _, err := readCgroupFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+		if err != nil {
+			return Limits{}, err
+		}
+		if limits.CPUs, err = ratio(quota, period); err != nil {
+			return Limits{}, fmt.Errorf("unable to parse cpu.cfs_quota_us %q and cpu.cfs_period_us %q\n%w", quota, period, err)
+		}
+	}
+
+	for _, file := range []string{filepath.Join(root, "memory.max"), filepath.Join(root, "memory", "memory.limit_in_bytes")} {
+		s, ok, err := readCgroupFile(file)
+		if err != nil {
+			return Limits{}, err
+		} else if !ok {
+			continue
+		}
+
+		if s != "max" {
+			if limits.Memory, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return Limits{}, fmt.Errorf("unable to parse %s %q\n%w", file, s, err)
+			}
+			// cgroup v1 reports no limit as a page aligned maximum value
+			if limits.Memory >= math.MaxInt64/4096*4096 {
+				limits.Memory = 0
+			}
+		}
+		break
+	}
+
+	return limits, nil
+}
+
+func readCgroupFile(file string) (string, bool, error) {
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	return strings.TrimSpace(string(b)), true, nil
+}
+
+func ratio(quota string, period string) (float64, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, err
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p <= 0 {
+		return 0, fmt.Errorf("period must be positive")
+	}
+
+	return q / p, nil
+}
+
+func formatCPUs(cpus float64) string {
+	if cpus == 0 {
+		return fmt.Sprintf("unlimited (%d available)", runtime.NumCPU())
+	}
+	return strconv.FormatFloat(cpus, 'f', -1, 64)
+}
+
+func formatMemory(memory int64) string {
+	if memory == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%dM", memory/1024/1024)
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testConnectorSizing(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		catalinaBase string
+		cgroup       string
+		c            helper.ConnectorSizing
+		serverXML    string
+	)
+
+	const server = `<Service name='Catalina'>
+    <Connector port='8080' bindOnInit='false' connectionTimeout='20000'/>
+    <Connector port='8443' maxThreads='50' executor='tomcatThreadPool'/>
+</Service>
+`
+
+	it.Before(func() {
+		var err error
+		catalinaBase, err = os.MkdirTemp("", "connector-sizing")
+		Expect(err).NotTo(HaveOccurred())
+		t.Setenv("CATALINA_BASE", catalinaBase)
+
+		serverXML = filepath.Join(catalinaBase, "conf", "server.xml")
+		Expect(os.MkdirAll(filepath.Dir(serverXML), 0755)).To(Succeed())
+		Expect(os.WriteFile(serverXML, []byte(server), 0644)).To(Succeed())
+
+		cgroup, err = os.MkdirTemp("", "cgroup")
+		Expect(err).NotTo(HaveOccurred())
+		c = helper.ConnectorSizing{CgroupRoot: cgroup}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(catalinaBase)).To(Succeed())
+		Expect(os.RemoveAll(cgroup)).To(Succeed())
+	})
+
+	it("reads cgroup v2 limits", func() {
+		Expect(os.WriteFile(filepath.Join(cgroup, "cpu.max"), []byte("150000 100000\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroup, "memory.max"), []byte("536870912\n"), 0644)).To(Succeed())
+
+		Expect(helper.ReadLimits(cgroup)).To(Equal(helper.Limits{CPUs: 1.5, Memory: 512 * 1024 * 1024}))
+	})
+
+	it("reads unlimited cgroup v2 limits", func() {
+		Expect(os.WriteFile(filepath.Join(cgroup, "cpu.max"), []byte("max 100000\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroup, "memory.max"), []byte("max\n"), 0644)).To(Succeed())
+
+		Expect(helper.ReadLimits(cgroup)).To(Equal(helper.Limits{}))
+	})
+
+	it("reads cgroup v1 limits", func() {
+		Expect(os.MkdirAll(filepath.Join(cgroup, "cpu"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(cgroup, "memory"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroup, "cpu", "cpu.cfs_quota_us"), []byte("200000\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroup, "cpu", "cpu.cfs_period_us"), []byte("100000\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroup, "memory", "memory.limit_in_bytes"), []byte("9223372036854771712\n"), 0644)).To(Succeed())
+
+		Expect(helper.ReadLimits(cgroup)).To(Equal(helper.Limits{CPUs: 2}))
+	})
+
+	it("sizes by CPU, memory and JVM thread count", func() {
+		Expect(helper.Size(helper.Limits{CPUs: 1, Memory: 4 * 1024 * 1024 * 1024}, 250)).To(Equal(50))
+		Expect(helper.Size(helper.Limits{CPUs: 1.5, Memory: 4 * 1024 * 1024 * 1024}, 250)).To(Equal(100))
+		Expect(helper.Size(helper.Limits{CPUs: 8, Memory: 256 * 1024 * 1024}, 250)).To(Equal(32))
+		Expect(helper.Size(helper.Limits{CPUs: 8, Memory: 4 * 1024 * 1024 * 1024}, 250)).To(Equal(200))
+		Expect(helper.Size(helper.Limits{CPUs: 8, Memory: 4 * 1024 * 1024 * 1024}, 100)).To(Equal(50))
+		Expect(helper.Size(helper.Limits{CPUs: 1, Memory: 32 * 1024 * 1024}, 250)).To(Equal(10))
+	})
+
+	it("does not size Connectors by default", func() {
+		Expect(os.WriteFile(filepath.Join(cgroup, "cpu.max"), []byte("100000 100000\n"), 0644)).To(Succeed())
+
+		Expect(c.Execute()).To(BeNil())
+	})
+
+	context("$BPL_TOMCAT_CONNECTOR_SIZING_ENABLED", func() {
+		it.Before(func() {
+			t.Setenv("BPL_TOMCAT_CONNECTOR_SIZING_ENABLED", "true")
+			t.Setenv("JAVA_TOOL_OPTIONS", "-Xss1M")
+		})
+
+		it("sizes Connectors with system properties", func() {
+			Expect(os.WriteFile(filepath.Join(cgroup, "cpu.max"), []byte("100000 100000\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(cgroup, "memory.max"), []byte("268435456\n"), 0644)).To(Succeed())
+
+			Expect(c.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-Xss1M -Dtomcat.connector.maxThreads=32 -Dtomcat.connector.acceptCount=32 -Dtomcat.connector.maxConnections=640",
+			}))
+			Expect(os.ReadFile(serverXML)).To(Equal([]byte(server)))
+		})
+
+		it("uses explicit configuration", func() {
+			Expect(os.WriteFile(filepath.Join(cgroup, "cpu.max"), []byte("100000 100000\n"), 0644)).To(Succeed())
+			t.Setenv("BPL_JVM_THREAD_COUNT", "300")
+			t.Setenv("BPL_TOMCAT_MAX_THREADS", "150")
+			t.Setenv("BPL_TOMCAT_ACCEPT_COUNT", "10")
+
+			Expect(c.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "-Xss1M -Dtomcat.connector.maxThreads=150 -Dtomcat.connector.acceptCount=10 -Dtomcat.connector.maxConnections=3000",
+			}))
+		})
+	})
+
+	it("uses explicit configuration without sizing", func() {
+		t.Setenv("BPL_TOMCAT_MAX_THREADS", "150")
+
+		Expect(c.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-Dtomcat.connector.maxThreads=150"}))
+	})
+
+	it("rejects invalid configuration", func() {
+		t.Setenv("BPL_TOMCAT_MAX_CONNECTIONS", "many")
+
+		_, err := c.Execute()
+		Expect(err).To(MatchError(ContainSubstring(`unable to parse $BPL_TOMCAT_MAX_CONNECTIONS "many"`)))
+	})
+}
@@ -25,6 +25,15 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("helper", spec.Report(report.Terminal{}))
+	suite("AppCDS", testAppCDS)
 	suite("AccessLoggingSupport", testAccessLoggingSupport)
+	suite("BindingPlaceholders", testBindingPlaceholders)
+	suite("ConnectorSizing", testConnectorSizing)
+	suite("ManagerUsers", testManagerUsers)
+	suite("OpenTelemetry", testOpenTelemetry)
+	suite("PlaceholderAudit", testPlaceholderAudit)
+	suite("Realm", testRealm)
+	suite("RemoteIP", testRemoteIP)
+	suite("VirtualThreads", testVirtualThreads)
 	suite.Run(t)
 }
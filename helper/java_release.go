@@ -0,0 +1,67 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JavaVersion returns the JAVA_VERSION of the JRE in $JAVA_HOME, read from its release file.
+func JavaVersion() (string, error) {
+	return javaRelease("JAVA_VERSION")
+}
+
+// JavaRuntimeVersion returns the JAVA_RUNTIME_VERSION, which identifies the build of the JRE in $JAVA_HOME, or failing
+// that its JAVA_VERSION, read from its release file.
+func JavaRuntimeVersion() (string, error) {
+	return javaRelease("JAVA_RUNTIME_VERSION", "JAVA_VERSION")
+}
+
+// javaRelease returns the value of the first of keys set in the release file of the JRE in $JAVA_HOME.
+func javaRelease(keys ...string) (string, error) {
+	javaHome, ok := os.LookupEnv("JAVA_HOME")
+	if !ok {
+		return "", fmt.Errorf("the Java version is unknown, $JAVA_HOME is not set")
+	}
+
+	file := filepath.Join(javaHome, "release")
+	in, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("the Java version is unknown, unable to open %s", file)
+	}
+	defer in.Close()
+
+	values := map[string]string{}
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		if k, v, ok := strings.Cut(s.Text(), "="); ok {
+			values[k] = strings.Trim(v, `"`)
+		}
+	}
+
+	for _, k := range keys {
+		if v, ok := values[k]; ok && v != "" {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("the Java version is unknown, no %s in %s", strings.Join(keys, " or "), file)
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/bindings"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	BindingTypeTomcatManager   = "tomcat-manager"
+	DefaultManagerAllowedCIDRs = "127.0.0.0/8,::1/128"
+	DefaultManagerRoles        = "manager-gui"
+)
+
+// ManagerUsers writes the tomcat-users.xml used by the manager webapp from a binding of type tomcat-manager and
+// configures the CIDRs the manager webapp can be accessed from.
+type ManagerUsers struct {
+	Bindings libcnb.Bindings
+	Logger   bard.Logger
+}
+
+func (m ManagerUsers) Execute() (map[string]string, error) {
+	var buf bytes.Buffer
+	buf.WriteString("<?xml version='1.0' encoding='utf-8'?>\n<tomcat-users>\n")
+
+	b, ok, err := bindings.ResolveOne(m.Bindings, bindings.OfType(BindingTypeTomcatManager))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve binding %s\n%w", BindingTypeTomcatManager, err)
+	} else if !ok {
+		m.Logger.Infof("WARNING: no binding of type %s found, the Tomcat manager cannot be accessed", BindingTypeTomcatManager)
+	} else {
+		username, ok := b.Secret["username"]
+		if !ok {
+			return nil, fmt.Errorf("binding %s has no username", b.Name)
+		}
+		password, ok := b.Secret["password"]
+		if !ok {
+			return nil, fmt.Errorf("binding %s has no password", b.Name)
+		}
+		roles := DefaultManagerRoles
+		if s, ok := b.Secret["roles"]; ok {
+			roles = s
+		}
+
+		m.Logger.Infof("Tomcat manager user %s with roles %s", strings.TrimSpace(username), strings.TrimSpace(roles))
+
+		buf.WriteString("    <user username='")
+		if err := xml.EscapeText(&buf, []byte(strings.TrimSpace(username))); err != nil {
+			return nil, fmt.Errorf("unable to escape username\n%w", err)
+		}
+		buf.WriteString("' password='")
+		if err := xml.EscapeText(&buf, []byte(strings.TrimSpace(password))); err != nil {
+			return nil, fmt.Errorf("unable to escape password\n%w", err)
+		}
+		buf.WriteString("' roles='")
+		if err := xml.EscapeText(&buf, []byte(strings.TrimSpace(roles))); err != nil {
+			return nil, fmt.Errorf("unable to escape roles\n%w", err)
+		}
+		buf.WriteString("'/>\n")
+	}
+
+	buf.WriteString("</tomcat-users>\n")
+
+	file := filepath.Join(os.TempDir(), "tomcat-manager-users.xml")
+	if err := os.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		return nil, fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	allow := strings.ReplaceAll(sherpa.GetEnvWithDefault("BPL_TOMCAT_MANAGER_ALLOWED_CIDRS", DefaultManagerAllowedCIDRs), " ", "")
+	m.Logger.Infof("Tomcat manager accessible from %s", allow)
+
+	var values []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		values = append(values, s)
+	}
+
+	values = append(values,
+		fmt.Sprintf("-Dtomcat.manager.users=%s", file),
+		fmt.Sprintf("-Dtomcat.manager.allow=%s", allow),
+	)
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
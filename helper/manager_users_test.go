@@ -0,0 +1,107 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testManagerUsers(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		m      helper.ManagerUsers
+		tmpDir string
+		users  string
+	)
+
+	it.Before(func() {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "manager-users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Setenv("TMPDIR", tmpDir)).To(Succeed())
+
+		users = filepath.Join(tmpDir, "tomcat-manager-users.xml")
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("TMPDIR")).To(Succeed())
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	it("writes no users without a binding", func() {
+		Expect(m.Execute()).To(Equal(map[string]string{
+			"JAVA_TOOL_OPTIONS": "-Dtomcat.manager.users=" + users + " -Dtomcat.manager.allow=127.0.0.0/8,::1/128",
+		}))
+		Expect(os.ReadFile(users)).To(Equal([]byte("<?xml version='1.0' encoding='utf-8'?>\n<tomcat-users>\n</tomcat-users>\n")))
+	})
+
+	context("binding", func() {
+		it.Before(func() {
+			m.Bindings = libcnb.Bindings{
+				{
+					Name: "manager",
+					Type: "tomcat-manager",
+					Secret: map[string]string{
+						"username": "admin",
+						"password": "s3cr'et<",
+					},
+				},
+			}
+		})
+
+		it("writes user from binding", func() {
+			_, err := m.Execute()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(users)).To(ContainSubstring("<user username='admin' password='s3cr&#39;et&lt;' roles='manager-gui'/>"))
+		})
+
+		it("fails without a password", func() {
+			delete(m.Bindings[0].Secret, "password")
+
+			_, err := m.Execute()
+			Expect(err).To(MatchError("binding manager has no password"))
+		})
+	})
+
+	context("$BPL_TOMCAT_MANAGER_ALLOWED_CIDRS", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_TOMCAT_MANAGER_ALLOWED_CIDRS", "10.0.0.0/8, 192.168.0.0/16")).To(Succeed())
+			Expect(os.Setenv("JAVA_TOOL_OPTIONS", "test-java-tool-options")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BPL_TOMCAT_MANAGER_ALLOWED_CIDRS")).To(Succeed())
+			Expect(os.Unsetenv("JAVA_TOOL_OPTIONS")).To(Succeed())
+		})
+
+		it("restricts access to configured CIDRs", func() {
+			Expect(m.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": "test-java-tool-options -Dtomcat.manager.users=" + users + " -Dtomcat.manager.allow=10.0.0.0/8,192.168.0.0/16",
+			}))
+		})
+	})
+}
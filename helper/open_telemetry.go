@@ -0,0 +1,76 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// DefaultOpenTelemetryPropagators are the propagators configured when $OTEL_PROPAGATORS is not set, W3C Trace Context
+// and Baggage.
+const DefaultOpenTelemetryPropagators = "tracecontext,baggage"
+
+// OpenTelemetry attaches the OpenTelemetry Java agent contributed to $CATALINA_BASE.  The agent is configured with the
+// standard OTEL_* environment variables.
+type OpenTelemetry struct {
+	Logger bard.Logger
+}
+
+func (o OpenTelemetry) Execute() (map[string]string, error) {
+	agent, ok := os.LookupEnv("BPI_TOMCAT_OTEL_AGENT")
+	if !ok {
+		return nil, fmt.Errorf("$BPI_TOMCAT_OTEL_AGENT must be set")
+	}
+
+	if strings.EqualFold(os.Getenv("OTEL_JAVAAGENT_ENABLED"), "false") {
+		o.Logger.Info("OpenTelemetry Java Agent disabled by $OTEL_JAVAAGENT_ENABLED")
+		return nil, nil
+	}
+
+	o.Logger.Info("OpenTelemetry Java Agent enabled")
+
+	var values []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		values = append(values, s)
+	}
+	values = append(values, fmt.Sprintf("-javaagent:%s", agent))
+
+	environment := map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}
+
+	if _, ok := os.LookupEnv("OTEL_PROPAGATORS"); !ok {
+		environment["OTEL_PROPAGATORS"] = DefaultOpenTelemetryPropagators
+	}
+
+	// values are not logged as they may contain credentials, such as client keys, headers or endpoint user info
+	var names []string
+	for _, e := range os.Environ() {
+		if name, _, _ := strings.Cut(e, "="); strings.HasPrefix(name, "OTEL_") {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		o.Logger.Infof("  Configured with %s", strings.Join(names, ", "))
+	}
+
+	return environment, nil
+}
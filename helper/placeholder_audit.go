@@ -0,0 +1,315 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	PlaceholderAuditDisabled = "disabled"
+	PlaceholderAuditFail     = "fail"
+	PlaceholderAuditWarn     = "warn"
+)
+
+// OptionalPlaceholders are placeholders in the contributed configuration that are deliberately left unresolved when a
+// feature is disabled.
+var OptionalPlaceholders = []string{"access.logging.enabled"}
+
+// JVMPropertyPrefixes are the prefixes of system properties that are always set by the JVM or catalina.sh.
+var JVMPropertyPrefixes = []string{"catalina.", "file.", "java.", "jdk.", "line.", "os.", "path.", "sun.", "user."}
+
+var (
+	placeholder    = regexp.MustCompile(`\$\{([^}]+)\}`)
+	propertyKey    = regexp.MustCompile(`^\s*([^#!\s=:][^\s=:]*)`)
+	systemProperty = regexp.MustCompile(`(?:^|[\s"'])-D([^=\s"']+)`)
+	xmlComment     = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// Placeholder is a ${...} placeholder in a configuration file.
+type Placeholder struct {
+	File string
+	Line int
+	Name string
+}
+
+func (p Placeholder) String() string {
+	return fmt.Sprintf("%s:%d ${%s}", p.File, p.Line, p.Name)
+}
+
+// PlaceholderAudit reports the ${...} placeholders in the configuration of $CATALINA_BASE that neither a system
+// property, a key in conf/catalina.properties nor, when EnvironmentPropertySource or ServiceBindingPropertySource are
+// enabled, an environment variable or binding resolves.  System properties are read from $JAVA_TOOL_OPTIONS,
+// $JAVA_OPTS, $CATALINA_OPTS and the -D flags in bin/setenv.sh.  Defaults declared in
+// $BPL_TOMCAT_PLACEHOLDER_DEFAULTS are applied as system properties.  Unresolved placeholders are logged as warnings
+// unless $BPL_TOMCAT_PLACEHOLDER_AUDIT is fail or disabled.
+type PlaceholderAudit struct {
+	Logger bard.Logger
+}
+
+func (p PlaceholderAudit) Execute() (map[string]string, error) {
+	mode := strings.ToLower(sherpa.GetEnvWithDefault("BPL_TOMCAT_PLACEHOLDER_AUDIT", PlaceholderAuditWarn))
+	switch mode {
+	case PlaceholderAuditDisabled:
+		return nil, nil
+	case PlaceholderAuditFail, PlaceholderAuditWarn:
+	default:
+		return nil, fmt.Errorf("$BPL_TOMCAT_PLACEHOLDER_AUDIT must be %s, %s or %s, found %q",
+			PlaceholderAuditFail, PlaceholderAuditWarn, PlaceholderAuditDisabled, mode)
+	}
+
+	catalinaBase, ok := os.LookupEnv("CATALINA_BASE")
+	if !ok {
+		return nil, fmt.Errorf("$CATALINA_BASE must be set")
+	}
+
+	placeholders, err := FindPlaceholders(catalinaBase)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := parseDefaults(os.Getenv("BPL_TOMCAT_PLACEHOLDER_DEFAULTS"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse $BPL_TOMCAT_PLACEHOLDER_DEFAULTS\n%w", err)
+	}
+
+	properties := map[string]bool{}
+	for _, name := range []string{"JAVA_TOOL_OPTIONS", "JAVA_OPTS", "CATALINA_OPTS"} {
+		for _, m := range systemProperty.FindAllStringSubmatch(os.Getenv(name), -1) {
+			properties[m[1]] = true
+		}
+	}
+	if err := catalinaProperties(catalinaBase, properties); err != nil {
+		return nil, err
+	}
+	if err := setenvProperties(catalinaBase, properties); err != nil {
+		return nil, err
+	}
+	environment := strings.Contains(os.Getenv("CATALINA_OPTS"), "EnvironmentPropertySource")
+	serviceBinding := strings.Contains(os.Getenv("CATALINA_OPTS"), "ServiceBindingPropertySource")
+
+	var (
+		applied    []string
+		unresolved []string
+	)
+	for _, ph := range placeholders {
+		if p.resolved(ph.Name, properties, environment, serviceBinding) {
+			continue
+		}
+
+		if v, ok := defaults[ph.Name]; ok {
+			applied = append(applied, fmt.Sprintf("-D%s=%s", ph.Name, v))
+			properties[ph.Name] = true
+			continue
+		}
+
+		unresolved = append(unresolved, ph.String())
+	}
+
+	if len(unresolved) > 0 {
+		msg := fmt.Sprintf("unresolved placeholders in Tomcat configuration:\n  %s", strings.Join(unresolved, "\n  "))
+		if mode == PlaceholderAuditFail {
+			return nil, fmt.Errorf("%s\nset the variables, declare defaults in $BPL_TOMCAT_PLACEHOLDER_DEFAULTS or set $BPL_TOMCAT_PLACEHOLDER_AUDIT to %s", msg, PlaceholderAuditWarn)
+		}
+		p.Logger.Infof("WARNING: %s", msg)
+	}
+
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	sort.Strings(applied)
+	p.Logger.Infof("Tomcat placeholder defaults: %s", strings.Join(applied, " "))
+
+	var values []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		values = append(values, s)
+	}
+	values = append(values, applied...)
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
+
+func (PlaceholderAudit) resolved(name string, properties map[string]bool, environment bool, serviceBinding bool) bool {
+	// ${name:-default} declares its own default
+	if strings.Contains(name, ":-") {
+		return true
+	}
+
+	if properties[name] {
+		return true
+	}
+
+	for _, o := range OptionalPlaceholders {
+		if name == o {
+			return true
+		}
+	}
+
+	for _, p := range JVMPropertyPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+
+	if environment {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
+		}
+	}
+
+	// ${<binding>.<key>} and ${chomp:<binding>.<key>} read $SERVICE_BINDING_ROOT/<binding>/<key>, and
+	// ${binding:<binding>:<key>} is resolved by the binding-placeholders helper
+	if root, ok := os.LookupEnv("SERVICE_BINDING_ROOT"); ok && serviceBinding {
+		binding, key, ok := strings.Cut(strings.TrimPrefix(name, "chomp:"), ".")
+		if strings.HasPrefix(name, BindingPlaceholderPrefix) {
+			binding, key, ok = strings.Cut(strings.TrimPrefix(name, BindingPlaceholderPrefix), ":")
+		}
+		if ok {
+			if _, err := os.Stat(filepath.Join(root, binding, key)); err == nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// FindPlaceholders returns the placeholders outside of comments in the XML files of $CATALINA_BASE/conf and
+// $CATALINA_BASE/conf/Catalina/localhost.
+func FindPlaceholders(catalinaBase string) ([]Placeholder, error) {
+	var files []string
+	for _, pattern := range []string{
+		filepath.Join(catalinaBase, "conf", "*.xml"),
+		filepath.Join(catalinaBase, "conf", "Catalina", "localhost", "*.xml"),
+	} {
+		f, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list %s\n%w", pattern, err)
+		}
+		files = append(files, f...)
+	}
+	sort.Strings(files)
+
+	var placeholders []Placeholder
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+
+		// blank out comments, keeping line numbers
+		s := xmlComment.ReplaceAllStringFunc(string(b), func(c string) string {
+			return strings.Repeat("\n", strings.Count(c, "\n"))
+		})
+
+		for _, m := range placeholder.FindAllStringSubmatchIndex(s, -1) {
+			placeholders = append(placeholders, Placeholder{
+				File: file,
+				Line: strings.Count(s[:m[0]], "\n") + 1,
+				Name: s[m[2]:m[3]],
+			})
+		}
+	}
+
+	return placeholders, nil
+}
+
+// catalinaProperties adds the keys of $CATALINA_BASE/conf/catalina.properties, which Tomcat sets as system properties
+// at startup.
+func catalinaProperties(catalinaBase string, properties map[string]bool) error {
+	file := filepath.Join(catalinaBase, "conf", "catalina.properties")
+
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	continued := false
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !continued {
+			if m := propertyKey.FindStringSubmatch(line); m != nil {
+				properties[m[1]] = true
+			}
+		}
+		// an odd number of trailing backslashes continues the value on the next line
+		continued = (len(line)-len(strings.TrimRight(line, "\\")))%2 == 1
+	}
+
+	return nil
+}
+
+// setenvProperties adds the -D flags in bin/setenv.sh, which catalina.sh sources from $CATALINA_BASE or, if it does not
+// exist there, $CATALINA_HOME.  Only flags on uncommented lines are read; the script is not evaluated.
+func setenvProperties(catalinaBase string, properties map[string]bool) error {
+	for _, root := range []string{catalinaBase, os.Getenv("CATALINA_HOME")} {
+		if root == "" {
+			continue
+		}
+
+		file := filepath.Join(root, "bin", "setenv.sh")
+		b, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("unable to read %s\n%w", file, err)
+		}
+
+		for _, line := range strings.Split(string(b), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			for _, m := range systemProperty.FindAllStringSubmatch(line, -1) {
+				properties[m[1]] = true
+			}
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+func parseDefaults(s string) (map[string]string, error) {
+	defaults := map[string]string{}
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("default %q must be <name>=<value>", entry)
+		}
+		defaults[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return defaults, nil
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testPlaceholderAudit(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		catalinaBase string
+		p            = helper.PlaceholderAudit{}
+		serverXML    string
+	)
+
+	it.Before(func() {
+		var err error
+		catalinaBase, err = os.MkdirTemp("", "placeholder-audit")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Setenv("CATALINA_BASE", catalinaBase)).To(Succeed())
+		Expect(os.Setenv("BPL_TOMCAT_PLACEHOLDER_AUDIT", "fail")).To(Succeed())
+
+		serverXML = filepath.Join(catalinaBase, "conf", "server.xml")
+		Expect(os.MkdirAll(filepath.Dir(serverXML), 0755)).To(Succeed())
+		Expect(os.WriteFile(serverXML, []byte(`<Server port='-1'>
+    <!-- ${COMMENTED} -->
+    <Connector port='${PORT}' address='${ADDRESS:-0.0.0.0}'/>
+    <Valve directory='${java.io.tmpdir}/logs' enabled='${access.logging.enabled}'/>
+</Server>
+`), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("CATALINA_BASE")).To(Succeed())
+		Expect(os.Unsetenv("BPL_TOMCAT_PLACEHOLDER_AUDIT")).To(Succeed())
+		Expect(os.RemoveAll(catalinaBase)).To(Succeed())
+	})
+
+	it("finds placeholders outside of comments", func() {
+		Expect(helper.FindPlaceholders(catalinaBase)).To(Equal([]helper.Placeholder{
+			{File: serverXML, Line: 3, Name: "PORT"},
+			{File: serverXML, Line: 3, Name: "ADDRESS:-0.0.0.0"},
+			{File: serverXML, Line: 4, Name: "java.io.tmpdir"},
+			{File: serverXML, Line: 4, Name: "access.logging.enabled"},
+		}))
+	})
+
+	it("fails with unresolved placeholders", func() {
+		_, err := p.Execute()
+		Expect(err).To(MatchError(ContainSubstring(serverXML + ":3 ${PORT}")))
+	})
+
+	context("$BPL_TOMCAT_PLACEHOLDER_AUDIT", func() {
+		it("warns with unresolved placeholders by default", func() {
+			Expect(os.Unsetenv("BPL_TOMCAT_PLACEHOLDER_AUDIT")).To(Succeed())
+
+			Expect(p.Execute()).To(BeNil())
+		})
+
+		it("warns with unresolved placeholders", func() {
+			Expect(os.Setenv("BPL_TOMCAT_PLACEHOLDER_AUDIT", "warn")).To(Succeed())
+
+			Expect(p.Execute()).To(BeNil())
+		})
+	})
+
+	context("system property", func() {
+		it.Before(func() {
+			Expect(os.Setenv("JAVA_TOOL_OPTIONS", "-Xmx1G -DPORT=8080")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("JAVA_TOOL_OPTIONS")).To(Succeed())
+		})
+
+		it("resolves placeholders", func() {
+			Expect(p.Execute()).To(BeNil())
+		})
+	})
+
+	context("catalina.properties", func() {
+		it("resolves placeholders", func() {
+			Expect(os.WriteFile(filepath.Join(catalinaBase, "conf", "catalina.properties"), []byte(`# PORT=8080
+common.loader=a,\
+  PORT
+PORT = 8080
+`), 0644)).To(Succeed())
+
+			Expect(p.Execute()).To(BeNil())
+		})
+
+		it("does not resolve placeholders from comments or continuation lines", func() {
+			Expect(os.WriteFile(filepath.Join(catalinaBase, "conf", "catalina.properties"), []byte(`# PORT=8080
+common.loader=a,\
+  PORT
+`), 0644)).To(Succeed())
+
+			_, err := p.Execute()
+			Expect(err).To(MatchError(ContainSubstring("${PORT}")))
+		})
+	})
+
+	context("setenv.sh", func() {
+		var setenv string
+
+		it.Before(func() {
+			setenv = filepath.Join(catalinaBase, "bin", "setenv.sh")
+			Expect(os.MkdirAll(filepath.Dir(setenv), 0755)).To(Succeed())
+		})
+
+		it("resolves placeholders", func() {
+			Expect(os.WriteFile(setenv, []byte(`CATALINA_OPTS="$CATALINA_OPTS -DPORT=8080"
+`), 0755)).To(Succeed())
+
+			Expect(p.Execute()).To(BeNil())
+		})
+
+		it("does not resolve placeholders from comments", func() {
+			Expect(os.WriteFile(setenv, []byte(`# CATALINA_OPTS="$CATALINA_OPTS -DPORT=8080"
+`), 0755)).To(Succeed())
+
+			_, err := p.Execute()
+			Expect(err).To(MatchError(ContainSubstring("${PORT}")))
+		})
+	})
+
+	context("environment variable", func() {
+		it.Before(func() {
+			Expect(os.Setenv("PORT", "8080")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("PORT")).To(Succeed())
+			Expect(os.Unsetenv("CATALINA_OPTS")).To(Succeed())
+		})
+
+		it("resolves placeholders with EnvironmentPropertySource", func() {
+			Expect(os.Setenv("CATALINA_OPTS", "-Dorg.apache.tomcat.util.digester.PROPERTY_SOURCE=org.apache.tomcat.util.digester.EnvironmentPropertySource")).To(Succeed())
+
+			Expect(p.Execute()).To(BeNil())
+		})
+
+		it("does not resolve placeholders without EnvironmentPropertySource", func() {
+			_, err := p.Execute()
+			Expect(err).To(MatchError(ContainSubstring("${PORT}")))
+		})
+	})
+
+	context("service binding", func() {
+		var root string
+
+		it.Before(func() {
+			var err error
+			root, err = os.MkdirTemp("", "bindings")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(root, "server"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(root, "server", "port"), []byte("8080\n"), 0644)).To(Succeed())
+
+			Expect(os.WriteFile(serverXML, []byte(`<Connector port='${chomp:server.port}'/>`), 0644)).To(Succeed())
+			Expect(os.Setenv("SERVICE_BINDING_ROOT", root)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("SERVICE_BINDING_ROOT")).To(Succeed())
+			Expect(os.Unsetenv("CATALINA_OPTS")).To(Succeed())
+			Expect(os.RemoveAll(root)).To(Succeed())
+		})
+
+		it("resolves placeholders with ServiceBindingPropertySource", func() {
+			Expect(os.Setenv("CATALINA_OPTS", "-Dorg.apache.tomcat.util.digester.PROPERTY_SOURCE=org.apache.tomcat.util.digester.ServiceBindingPropertySource")).To(Succeed())
+
+			Expect(p.Execute()).To(BeNil())
+		})
+
+		it("resolves ${binding:<binding>:<key>} placeholders with ServiceBindingPropertySource", func() {
+			Expect(os.WriteFile(serverXML, []byte(`<Connector port='${binding:server:port}'/>`), 0644)).To(Succeed())
+			Expect(os.Setenv("CATALINA_OPTS", "-Dorg.apache.tomcat.util.digester.PROPERTY_SOURCE=org.apache.tomcat.util.digester.ServiceBindingPropertySource")).To(Succeed())
+
+			Expect(p.Execute()).To(BeNil())
+		})
+
+		it("does not resolve placeholders without ServiceBindingPropertySource", func() {
+			_, err := p.Execute()
+			Expect(err).To(MatchError(ContainSubstring("${chomp:server.port}")))
+		})
+	})
+
+	context("$BPL_TOMCAT_PLACEHOLDER_DEFAULTS", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BPL_TOMCAT_PLACEHOLDER_DEFAULTS", "PORT=8080")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BPL_TOMCAT_PLACEHOLDER_DEFAULTS")).To(Succeed())
+		})
+
+		it("applies defaults", func() {
+			Expect(p.Execute()).To(Equal(map[string]string{"JAVA_TOOL_OPTIONS": "-DPORT=8080"}))
+		})
+	})
+}
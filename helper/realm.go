@@ -0,0 +1,286 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/bindings"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
+)
+
+const (
+	BindingTypeTomcatUsers           = "tomcat-users"
+	BindingTypeTomcatDataSourceRealm = "tomcat-datasource-realm"
+	BindingTypeLDAP                  = "ldap"
+
+	realmDataSource = "jdbc/realm"
+)
+
+// Realm configures a Realm, wrapped in a LockOutRealm, in the Engine of server.xml from a binding of type tomcat-users
+// (MemoryRealm), tomcat-datasource-realm (DataSourceRealm) or ldap (JNDIRealm).  $CATALINA_BASE may be read-only, so
+// the Realm is configured in a copy of $CATALINA_BASE/conf in a writable CATALINA_BASE that links to the other entries
+// of $CATALINA_BASE, and $CATALINA_BASE is changed to it.
+type Realm struct {
+	Bindings libcnb.Bindings
+	Logger   bard.Logger
+}
+
+func (r Realm) Execute() (map[string]string, error) {
+	b := bindings.Resolve(r.Bindings, func(b libcnb.Binding) bool {
+		t := strings.ToLower(b.Type)
+		return t == BindingTypeTomcatUsers || t == BindingTypeTomcatDataSourceRealm || t == BindingTypeLDAP
+	})
+
+	if len(b) == 0 {
+		return nil, nil
+	} else if len(b) > 1 {
+		var names []string
+		for _, binding := range b {
+			names = append(names, binding.Name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("multiple realm bindings found: %s", strings.Join(names, ", "))
+	}
+
+	catalinaBase, ok := os.LookupEnv("CATALINA_BASE")
+	if !ok {
+		return nil, fmt.Errorf("$CATALINA_BASE must be set")
+	}
+
+	var (
+		err             error
+		resource, realm string
+	)
+	switch strings.ToLower(b[0].Type) {
+	case BindingTypeTomcatUsers:
+		realm, err = r.memoryRealm(b[0])
+	case BindingTypeTomcatDataSourceRealm:
+		resource, realm, err = r.dataSourceRealm(b[0])
+	case BindingTypeLDAP:
+		realm, err = r.jndiRealm(b[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := writableBase(catalinaBase, filepath.Join(os.TempDir(), "tomcat-catalina-base"))
+	if err != nil {
+		return nil, err
+	}
+
+	file := filepath.Join(base, "conf", "server.xml")
+	in, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+	s := string(in)
+
+	if resource != "" {
+		if util.Element("GlobalNamingResources").MatchString(s) {
+			s, err = insertIntoElement(s, "GlobalNamingResources", "\n        "+resource)
+		} else {
+			s, err = insertIntoElement(s, "Server", "\n    <GlobalNamingResources>\n        "+resource+"\n    </GlobalNamingResources>")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure %s\n%w", file, err)
+		}
+	}
+
+	if s, err = insertIntoElement(s, "Engine", "\n            <Realm className='org.apache.catalina.realm.LockOutRealm'>\n                "+realm+
+		"\n            </Realm>"); err != nil {
+		return nil, fmt.Errorf("unable to configure %s\n%w", file, err)
+	}
+
+	if err := os.WriteFile(file, []byte(s), 0600); err != nil {
+		return nil, fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return map[string]string{"CATALINA_BASE": base}, nil
+}
+
+func (r Realm) memoryRealm(binding libcnb.Binding) (string, error) {
+	path, ok := binding.SecretFilePath("tomcat-users.xml")
+	if !ok {
+		return "", fmt.Errorf("binding %s has no tomcat-users.xml", binding.Name)
+	}
+
+	r.Logger.Infof("Tomcat MemoryRealm from binding %s", binding.Name)
+	return element("Realm", [][2]string{
+		{"className", "org.apache.catalina.realm.MemoryRealm"},
+		{"pathname", path},
+	})
+}
+
+func (r Realm) dataSourceRealm(binding libcnb.Binding) (string, string, error) {
+	url, ok := binding.Secret["jdbc-url"]
+	if !ok {
+		return "", "", fmt.Errorf("binding %s has no jdbc-url", binding.Name)
+	}
+
+	resource := [][2]string{
+		{"name", realmDataSource},
+		{"auth", "Container"},
+		{"type", "javax.sql.DataSource"},
+		{"url", strings.TrimSpace(url)},
+	}
+	for _, k := range [][2]string{
+		{"driver-class-name", "driverClassName"},
+		{"username", "username"},
+		{"password", "password"},
+	} {
+		if v, ok := binding.Secret[k[0]]; ok {
+			resource = append(resource, [2]string{k[1], strings.TrimSpace(v)})
+		}
+	}
+
+	realm := [][2]string{
+		{"className", "org.apache.catalina.realm.DataSourceRealm"},
+		{"dataSourceName", realmDataSource},
+	}
+	for _, k := range [][2]string{
+		{"user-table", "userTable"},
+		{"user-name-col", "userNameCol"},
+		{"user-cred-col", "userCredCol"},
+		{"user-role-table", "userRoleTable"},
+		{"role-name-col", "roleNameCol"},
+	} {
+		v, ok := binding.Secret[k[0]]
+		if !ok {
+			return "", "", fmt.Errorf("binding %s has no %s", binding.Name, k[0])
+		}
+		realm = append(realm, [2]string{k[1], strings.TrimSpace(v)})
+	}
+
+	r.Logger.Infof("Tomcat DataSourceRealm from binding %s", binding.Name)
+
+	re, err := element("Resource", resource)
+	if err != nil {
+		return "", "", err
+	}
+	rl, err := element("Realm", realm)
+	if err != nil {
+		return "", "", err
+	}
+	return re, rl, nil
+}
+
+func (r Realm) jndiRealm(binding libcnb.Binding) (string, error) {
+	urlList := strings.Fields(strings.ReplaceAll(binding.Secret["urls"], ",", " "))
+	if len(urlList) == 0 {
+		return "", fmt.Errorf("binding %s has no urls", binding.Name)
+	}
+
+	realm := [][2]string{
+		{"className", "org.apache.catalina.realm.JNDIRealm"},
+		{"connectionURL", urlList[0]},
+	}
+	if len(urlList) > 1 {
+		realm = append(realm, [2]string{"alternateURL", urlList[1]})
+	}
+
+	for _, k := range [][2]string{
+		{"username", "connectionName"},
+		{"password", "connectionPassword"},
+		{"user-pattern", "userPattern"},
+		{"user-base", "userBase"},
+		{"user-search", "userSearch"},
+		{"role-base", "roleBase"},
+		{"role-name", "roleName"},
+		{"role-search", "roleSearch"},
+	} {
+		if v, ok := binding.Secret[k[0]]; ok {
+			realm = append(realm, [2]string{k[1], strings.TrimSpace(v)})
+		}
+	}
+
+	r.Logger.Infof("Tomcat JNDIRealm from binding %s", binding.Name)
+	return element("Realm", realm)
+}
+
+func element(name string, attributes [][2]string) (string, error) {
+	var b bytes.Buffer
+
+	b.WriteString("<" + name)
+	for _, a := range attributes {
+		b.WriteString(" " + a[0] + "='")
+		if err := xml.EscapeText(&b, []byte(a[1])); err != nil {
+			return "", fmt.Errorf("unable to escape %s\n%w", a[0], err)
+		}
+		b.WriteString("'")
+	}
+	b.WriteString("/>")
+
+	return b.String(), nil
+}
+
+// insertIntoElement inserts text at the start of the content of the first element called name, turning an empty
+// element tag into a start and an end tag.
+func insertIntoElement(s string, name string, text string) (string, error) {
+	m := util.Element(name).FindStringIndex(s)
+	if m == nil {
+		return "", fmt.Errorf("unable to find %s", name)
+	}
+
+	tag := s[m[0]:m[1]]
+	if !strings.HasSuffix(tag, "/>") {
+		return s[:m[1]] + text + s[m[1]:], nil
+	}
+
+	indent := s[strings.LastIndex(s[:m[0]], "\n")+1 : m[0]]
+	indent = indent[:len(indent)-len(strings.TrimLeft(indent, " \t"))]
+	return s[:m[0]] + strings.TrimRight(strings.TrimSuffix(tag, "/>"), " \t\r\n") + ">" + text + "\n" + indent + "</" + name + ">" + s[m[1]:], nil
+}
+
+// writableBase creates destination, replacing any previous contents, as a CATALINA_BASE with a copy of the conf
+// directory of catalinaBase and links to its other entries.
+func writableBase(catalinaBase string, destination string) (string, error) {
+	if err := os.RemoveAll(destination); err != nil {
+		return "", fmt.Errorf("unable to remove %s\n%w", destination, err)
+	}
+	if err := os.MkdirAll(destination, 0700); err != nil {
+		return "", fmt.Errorf("unable to create directory %s\n%w", destination, err)
+	}
+
+	entries, err := os.ReadDir(catalinaBase)
+	if err != nil {
+		return "", fmt.Errorf("unable to read directory %s\n%w", catalinaBase, err)
+	}
+
+	for _, e := range entries {
+		source, file := filepath.Join(catalinaBase, e.Name()), filepath.Join(destination, e.Name())
+		if e.Name() == "conf" {
+			if err := sherpa.CopyDir(source, file); err != nil {
+				return "", fmt.Errorf("unable to copy %s to %s\n%w", source, file, err)
+			}
+		} else if err := os.Symlink(source, file); err != nil {
+			return "", fmt.Errorf("unable to create symlink from %s to %s\n%w", source, file, err)
+		}
+	}
+
+	return destination, nil
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testRealm(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		catalinaBase string
+		r            helper.Realm
+		serverXML    string
+		tmp          string
+	)
+
+	const server = `<Server port='-1'>
+    <Service name='Catalina'>
+        <Engine defaultHost='localhost' name='Catalina'>
+            <Host name='localhost'/>
+        </Engine>
+    </Service>
+</Server>
+`
+
+	it.Before(func() {
+		var err error
+		catalinaBase, err = os.MkdirTemp("", "realm")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Setenv("CATALINA_BASE", catalinaBase)).To(Succeed())
+
+		tmp, err = os.MkdirTemp("", "realm-tmp")
+		Expect(err).NotTo(HaveOccurred())
+		t.Setenv("TMPDIR", tmp)
+
+		serverXML = filepath.Join(catalinaBase, "conf", "server.xml")
+		Expect(os.MkdirAll(filepath.Dir(serverXML), 0755)).To(Succeed())
+		Expect(os.WriteFile(serverXML, []byte(server), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(catalinaBase, "webapps"), 0755)).To(Succeed())
+
+		r = helper.Realm{}
+	})
+
+	it.After(func() {
+		Expect(os.Unsetenv("CATALINA_BASE")).To(Succeed())
+		Expect(os.RemoveAll(catalinaBase)).To(Succeed())
+		Expect(os.RemoveAll(tmp)).To(Succeed())
+	})
+
+	it("does not change CATALINA_BASE without a binding", func() {
+		Expect(r.Execute()).To(BeNil())
+		Expect(filepath.Join(tmp, "tomcat-catalina-base")).NotTo(BeAnExistingFile())
+	})
+
+	it("configures MemoryRealm in a writable CATALINA_BASE", func() {
+		r.Bindings = libcnb.Bindings{
+			{Name: "users", Path: "/bindings/users", Type: "tomcat-users", Secret: map[string]string{"tomcat-users.xml": ""}},
+		}
+
+		base := filepath.Join(tmp, "tomcat-catalina-base")
+		Expect(r.Execute()).To(Equal(map[string]string{"CATALINA_BASE": base}))
+
+		Expect(os.ReadFile(serverXML)).To(Equal([]byte(server)))
+		Expect(os.Readlink(filepath.Join(base, "webapps"))).To(Equal(filepath.Join(catalinaBase, "webapps")))
+		Expect(os.ReadFile(filepath.Join(base, "conf", "server.xml"))).To(Equal([]byte(`<Server port='-1'>
+    <Service name='Catalina'>
+        <Engine defaultHost='localhost' name='Catalina'>
+            <Realm className='org.apache.catalina.realm.LockOutRealm'>
+                <Realm className='org.apache.catalina.realm.MemoryRealm' pathname='/bindings/users/tomcat-users.xml'/>
+            </Realm>
+            <Host name='localhost'/>
+        </Engine>
+    </Service>
+</Server>
+`)))
+	})
+
+	it("configures DataSourceRealm", func() {
+		r.Bindings = libcnb.Bindings{
+			{Name: "db", Type: "tomcat-datasource-realm", Secret: map[string]string{
+				"jdbc-url":          "jdbc:postgresql://db/users?ssl=true&x=1",
+				"driver-class-name": "org.postgresql.Driver",
+				"username":          "tomcat",
+				"password":          "secret",
+				"user-table":        "users",
+				"user-name-col":     "user_name",
+				"user-cred-col":     "user_pass",
+				"user-role-table":   "user_roles",
+				"role-name-col":     "role_name",
+			}},
+		}
+
+		env, err := r.Execute()
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := os.ReadFile(filepath.Join(env["CATALINA_BASE"], "conf", "server.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring(`<Server port='-1'>
+    <GlobalNamingResources>
+        <Resource name='jdbc/realm' auth='Container' type='javax.sql.DataSource' url='jdbc:postgresql://db/users?ssl=true&amp;x=1' driverClassName='org.postgresql.Driver' username='tomcat' password='secret'/>
+    </GlobalNamingResources>
+    <Service name='Catalina'>`))
+		Expect(string(b)).To(ContainSubstring(`<Realm className='org.apache.catalina.realm.DataSourceRealm' dataSourceName='jdbc/realm' userTable='users' userNameCol='user_name' userCredCol='user_pass' userRoleTable='user_roles' roleNameCol='role_name'/>`))
+	})
+
+	it("adds the DataSource to existing GlobalNamingResources", func() {
+		Expect(os.WriteFile(serverXML, []byte(`<Server port='-1'>
+    <GlobalNamingResources>
+        <Resource name='UserDatabase' auth='Container'/>
+    </GlobalNamingResources>
+    <Service name='Catalina'>
+        <EngineValve/>
+        <Engine defaultHost='localhost' name='Catalina'/>
+    </Service>
+</Server>
+`), 0644)).To(Succeed())
+		r.Bindings = libcnb.Bindings{
+			{Name: "db", Type: "tomcat-datasource-realm", Secret: map[string]string{
+				"jdbc-url":        "jdbc:postgresql://db/users",
+				"user-table":      "users",
+				"user-name-col":   "user_name",
+				"user-cred-col":   "user_pass",
+				"user-role-table": "user_roles",
+				"role-name-col":   "role_name",
+			}},
+		}
+
+		env, err := r.Execute()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.ReadFile(filepath.Join(env["CATALINA_BASE"], "conf", "server.xml"))).To(Equal([]byte(`<Server port='-1'>
+    <GlobalNamingResources>
+        <Resource name='jdbc/realm' auth='Container' type='javax.sql.DataSource' url='jdbc:postgresql://db/users'/>
+        <Resource name='UserDatabase' auth='Container'/>
+    </GlobalNamingResources>
+    <Service name='Catalina'>
+        <EngineValve/>
+        <Engine defaultHost='localhost' name='Catalina'>
+            <Realm className='org.apache.catalina.realm.LockOutRealm'>
+                <Realm className='org.apache.catalina.realm.DataSourceRealm' dataSourceName='jdbc/realm' userTable='users' userNameCol='user_name' userCredCol='user_pass' userRoleTable='user_roles' roleNameCol='role_name'/>
+            </Realm>
+        </Engine>
+    </Service>
+</Server>
+`)))
+	})
+
+	it("configures JNDIRealm", func() {
+		r.Bindings = libcnb.Bindings{
+			{Name: "ldap", Type: "ldap", Secret: map[string]string{
+				"urls":         "ldap://one:389, ldap://two:389",
+				"user-pattern": "uid={0},ou=people,dc=example,dc=com",
+			}},
+		}
+
+		env, err := r.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.ReadFile(filepath.Join(env["CATALINA_BASE"], "conf", "server.xml"))).To(ContainSubstring(`<Realm className='org.apache.catalina.realm.JNDIRealm' connectionURL='ldap://one:389' alternateURL='ldap://two:389' userPattern='uid={0},ou=people,dc=example,dc=com'/>`))
+	})
+
+	it("replaces a previously configured CATALINA_BASE", func() {
+		r.Bindings = libcnb.Bindings{
+			{Name: "users", Path: "/bindings/users", Type: "tomcat-users", Secret: map[string]string{"tomcat-users.xml": ""}},
+		}
+
+		Expect(r.Execute()).NotTo(BeNil())
+		env, err := r.Execute()
+		Expect(err).NotTo(HaveOccurred())
+
+		b, err := os.ReadFile(filepath.Join(env["CATALINA_BASE"], "conf", "server.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(string(b), "MemoryRealm")).To(Equal(1))
+	})
+
+	it("fails with multiple realm bindings", func() {
+		r.Bindings = libcnb.Bindings{
+			{Name: "users", Type: "tomcat-users"},
+			{Name: "ldap", Type: "ldap"},
+		}
+
+		_, err := r.Execute()
+		Expect(err).To(MatchError("multiple realm bindings found: ldap, users"))
+	})
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// RemoteIP configures the RemoteIpValves in $CATALINA_BASE/conf/server.xml from $BPL_TOMCAT_REMOTE_IP_* variables.  The
+// proxies are configured as comma separated IPv4 and IPv6 CIDRs and IP addresses and converted to the regular
+// expressions that RemoteIpValve expects.  The values are added to $JAVA_TOOL_OPTIONS as the tomcat.remoteIp.* system
+// properties read by the placeholders the buildpack adds to the RemoteIpValves, so the configuration is not modified
+// at launch.  The RFC 7239 Forwarded header is not supported, RemoteIpValve only reads X-Forwarded-* style headers.
+type RemoteIP struct {
+	Logger bard.Logger
+}
+
+func (r RemoteIP) Execute() (map[string]string, error) {
+	var properties []string
+
+	for _, c := range []struct {
+		name      string
+		attribute string
+		proxies   bool
+	}{
+		{"BPL_TOMCAT_REMOTE_IP_INTERNAL_PROXIES", "internalProxies", true},
+		{"BPL_TOMCAT_REMOTE_IP_TRUSTED_PROXIES", "trustedProxies", true},
+		{"BPL_TOMCAT_REMOTE_IP_REMOTE_IP_HEADER", "remoteIpHeader", false},
+		{"BPL_TOMCAT_REMOTE_IP_PROTOCOL_HEADER", "protocolHeader", false},
+		{"BPL_TOMCAT_REMOTE_IP_HOST_HEADER", "hostHeader", false},
+		{"BPL_TOMCAT_REMOTE_IP_PORT_HEADER", "portHeader", false},
+	} {
+		s, ok := os.LookupEnv(c.name)
+		if !ok {
+			continue
+		}
+
+		s = strings.TrimSpace(s)
+		if strings.EqualFold(s, "forwarded") {
+			return nil, fmt.Errorf("%s cannot be forwarded, RemoteIpValve does not parse the RFC 7239 Forwarded header, "+
+				"configure the proxy to send X-Forwarded-* headers", c.name)
+		}
+
+		if c.proxies {
+			var err error
+			if s, err = ProxiesRegex(s); err != nil {
+				return nil, fmt.Errorf("unable to parse %s\n%w", c.name, err)
+			}
+		} else if strings.ContainsAny(s, " \t\r\n") {
+			return nil, fmt.Errorf("%s must be an HTTP header name, found %q", c.name, s)
+		}
+
+		properties = append(properties, fmt.Sprintf("-Dtomcat.remoteIp.%s=%s", c.attribute, s))
+	}
+
+	if len(properties) == 0 {
+		return nil, nil
+	}
+
+	r.Logger.Infof("Tomcat RemoteIpValve: %s", strings.Join(properties, " "))
+
+	var values []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		values = append(values, s)
+	}
+	values = append(values, properties...)
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
+
+// ProxiesRegex converts a comma separated list of IPv4 and IPv6 CIDRs and IP addresses into a regular expression
+// matching them.  IPv6 addresses are matched in the uncompressed form, such as 0:0:0:0:0:0:0:1, that Tomcat reports
+// remote addresses in.
+func ProxiesRegex(s string) (string, error) {
+	var alternatives []string
+
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		var network *net.IPNet
+		if ip := net.ParseIP(p); ip != nil {
+			if ip4 := ip.To4(); ip4 != nil {
+				network = &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}
+			} else {
+				network = &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+			}
+		} else if _, n, err := net.ParseCIDR(p); err == nil {
+			network = n
+		} else {
+			return "", fmt.Errorf("%q is not an IP address or CIDR", p)
+		}
+
+		if _, bits := network.Mask.Size(); bits == 32 {
+			alternatives = append(alternatives, cidrRegex(network))
+		} else {
+			alternatives = append(alternatives, ipv6CIDRRegex(network))
+		}
+	}
+
+	return strings.Join(alternatives, "|"), nil
+}
+
+func cidrRegex(network *net.IPNet) string {
+	ones, _ := network.Mask.Size()
+	ip := network.IP.To4()
+
+	var octets []string
+	for i := 0; i < 4; i++ {
+		bits := ones - i*8
+		switch {
+		case bits >= 8:
+			octets = append(octets, strconv.Itoa(int(ip[i])))
+		case bits <= 0:
+			octets = append(octets, `\d{1,3}`)
+		default:
+			lo := int(ip[i])
+			hi := lo + (1 << (8 - bits)) - 1
+
+			var values []string
+			for v := lo; v <= hi; v++ {
+				values = append(values, strconv.Itoa(v))
+			}
+			octets = append(octets, "(?:"+strings.Join(values, "|")+")")
+		}
+	}
+
+	return strings.Join(octets, `\.`)
+}
+
+func ipv6CIDRRegex(network *net.IPNet) string {
+	ones, _ := network.Mask.Size()
+	ip := network.IP.To16()
+
+	var groups []string
+	for i := 0; i < 8; i++ {
+		bits := ones - i*16
+		switch {
+		case bits <= 0:
+			groups = append(groups, `[0-9a-f]{1,4}`)
+		default:
+			groups = append(groups, hexGroupRegex(int(ip[2*i])<<8|int(ip[2*i+1]), min(bits, 16)))
+		}
+	}
+
+	// a link-local address may be followed by its zone
+	return strings.Join(groups, ":") + `(?:%[^%]+)?`
+}
+
+// hexGroupRegex returns a regular expression matching the hexadecimal representations, without leading zeros, of the
+// 16 bit values whose top fixed bits are those of value.
+func hexGroupRegex(value int, fixed int) string {
+	var nibbles [4][]int
+	for n := 0; n < 4; n++ {
+		f := min(max(fixed-4*n, 0), 4)
+		digit := (value >> (12 - 4*n)) & 0xf
+		for d := 0; d < 16; d++ {
+			if d>>(4-f) == digit>>(4-f) {
+				nibbles[n] = append(nibbles[n], d)
+			}
+		}
+	}
+
+	var alternatives []string
+	for length := 4; length >= 1; length-- {
+		// the leading nibbles that are omitted must be zero
+		if length < 4 && nibbles[3-length][0] != 0 {
+			break
+		}
+
+		first := nibbles[4-length]
+		if length > 1 && first[0] == 0 {
+			first = first[1:]
+		}
+		if len(first) == 0 {
+			continue
+		}
+
+		alternative := hexClass(first)
+		for _, n := range nibbles[5-length:] {
+			alternative += hexClass(n)
+		}
+		alternatives = append(alternatives, alternative)
+	}
+
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	return "(?:" + strings.Join(alternatives, "|") + ")"
+}
+
+func hexClass(digits []int) string {
+	if len(digits) == 16 {
+		return "[0-9a-f]"
+	}
+
+	var s string
+	for _, d := range digits {
+		s += strconv.FormatInt(int64(d), 16)
+	}
+	if len(digits) == 1 {
+		return s
+	}
+	return "[" + s + "]"
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testRemoteIP(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		r = helper.RemoteIP{}
+	)
+
+	it("does not set system properties if not configured", func() {
+		Expect(r.Execute()).To(BeNil())
+	})
+
+	context("$BPL_TOMCAT_REMOTE_IP_*", func() {
+		it.Before(func() {
+			t.Setenv("BPL_TOMCAT_REMOTE_IP_INTERNAL_PROXIES", "10.0.0.0/8, 192.168.1.1")
+			t.Setenv("BPL_TOMCAT_REMOTE_IP_REMOTE_IP_HEADER", "x-real-ip")
+			t.Setenv("BPL_TOMCAT_REMOTE_IP_PORT_HEADER", "x-forwarded-port")
+			t.Setenv("JAVA_TOOL_OPTIONS", "-Dtest=value")
+		})
+
+		it("configures RemoteIpValve with system properties", func() {
+			Expect(r.Execute()).To(Equal(map[string]string{
+				"JAVA_TOOL_OPTIONS": `-Dtest=value -Dtomcat.remoteIp.internalProxies=10\.\d{1,3}\.\d{1,3}\.\d{1,3}|192\.168\.1\.1 ` +
+					"-Dtomcat.remoteIp.remoteIpHeader=x-real-ip -Dtomcat.remoteIp.portHeader=x-forwarded-port",
+			}))
+		})
+	})
+
+	context("invalid header", func() {
+		it.Before(func() {
+			t.Setenv("BPL_TOMCAT_REMOTE_IP_HOST_HEADER", "x-forwarded host")
+		})
+
+		it("fails", func() {
+			_, err := r.Execute()
+			Expect(err).To(MatchError(`BPL_TOMCAT_REMOTE_IP_HOST_HEADER must be an HTTP header name, found "x-forwarded host"`))
+		})
+	})
+
+	context("Forwarded", func() {
+		it.Before(func() {
+			t.Setenv("BPL_TOMCAT_REMOTE_IP_REMOTE_IP_HEADER", "Forwarded")
+		})
+
+		it("fails", func() {
+			_, err := r.Execute()
+			Expect(err).To(MatchError(ContainSubstring("RemoteIpValve does not parse the RFC 7239 Forwarded header")))
+		})
+	})
+
+	it("converts IPv6 CIDRs to regular expressions", func() {
+		s, err := helper.ProxiesRegex("::1/128,fd00::/8,2001:db8:abcd:1000::/52")
+		Expect(err).NotTo(HaveOccurred())
+
+		re := regexp.MustCompile("^(?:" + s + ")$")
+		Expect(re.MatchString("0:0:0:0:0:0:0:1")).To(BeTrue())
+		Expect(re.MatchString("fd12:3:0:0:0:0:0:1")).To(BeTrue())
+		Expect(re.MatchString("fdff:ffff:ffff:ffff:ffff:ffff:ffff:ffff")).To(BeTrue())
+		Expect(re.MatchString("fe80:0:0:0:0:0:0:1%eth0")).To(BeFalse())
+		Expect(re.MatchString("2001:db8:abcd:1000:0:0:0:1")).To(BeTrue())
+		Expect(re.MatchString("2001:db8:abcd:1fff:0:0:0:1")).To(BeTrue())
+		Expect(re.MatchString("2001:db8:abcd:2000:0:0:0:1")).To(BeFalse())
+		Expect(re.MatchString("2001:db8:abcd:fff:0:0:0:1")).To(BeFalse())
+
+		s, err = helper.ProxiesRegex("fe80::/10")
+		Expect(err).NotTo(HaveOccurred())
+
+		re = regexp.MustCompile("^(?:" + s + ")$")
+		Expect(re.MatchString("fe80:0:0:0:0:0:0:1%eth0")).To(BeTrue())
+		Expect(re.MatchString("febf:0:0:0:0:0:0:1")).To(BeTrue())
+		Expect(re.MatchString("fec0:0:0:0:0:0:0:1")).To(BeFalse())
+	})
+
+	it("converts CIDRs to regular expressions", func() {
+		s, err := helper.ProxiesRegex("172.16.0.0/12")
+		Expect(err).NotTo(HaveOccurred())
+
+		re := regexp.MustCompile("^(?:" + s + ")$")
+		Expect(re.MatchString("172.16.0.1")).To(BeTrue())
+		Expect(re.MatchString("172.31.255.255")).To(BeTrue())
+		Expect(re.MatchString("172.32.0.1")).To(BeFalse())
+		Expect(re.MatchString("172.15.0.1")).To(BeFalse())
+
+		s, err = helper.ProxiesRegex("127.0.0.0/8,::1")
+		Expect(err).NotTo(HaveOccurred())
+
+		re = regexp.MustCompile("^(?:" + s + ")$")
+		Expect(re.MatchString("127.0.0.1")).To(BeTrue())
+		Expect(re.MatchString("0:0:0:0:0:0:0:1")).To(BeTrue())
+		Expect(re.MatchString("0:0:0:0:0:0:0:2")).To(BeFalse())
+		Expect(re.MatchString("128.0.0.1")).To(BeFalse())
+
+		_, err = helper.ProxiesRegex("proxy.example.com")
+		Expect(err).To(MatchError(`"proxy.example.com" is not an IP address or CIDR`))
+	})
+}
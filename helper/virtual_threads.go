@@ -0,0 +1,111 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+const (
+	VirtualThreadsDisabled = "false"
+	VirtualThreadsEnabled  = "true"
+	VirtualThreadsRequired = "required"
+
+	// VirtualThreadsProperty is the system property read by the useVirtualThreads placeholder the buildpack adds to
+	// the Connectors in $CATALINA_BASE/conf/server.xml.
+	VirtualThreadsProperty = "tomcat.connector.useVirtualThreads"
+
+	// VirtualThreadsMinimumJava is the first Java version with final virtual threads.
+	VirtualThreadsMinimumJava = 21
+
+	// VirtualThreadsMinimumTomcat is the first Tomcat line whose Connectors support useVirtualThreads.
+	VirtualThreadsMinimumTomcat = "10.1"
+)
+
+// VirtualThreads makes the Connectors process requests on virtual threads when $BPL_TOMCAT_VIRTUAL_THREADS is true or
+// required, by adding VirtualThreadsProperty to $JAVA_TOOL_OPTIONS so the configuration is not modified at launch.
+// Virtual threads need Tomcat VirtualThreadsMinimumTomcat, read from $BPI_TOMCAT_VERSION, and Java
+// VirtualThreadsMinimumJava, read from $JAVA_HOME/release.  When they are not supported the helper warns if
+// $BPL_TOMCAT_VIRTUAL_THREADS is true and fails if it is required.  Connectors that use an Executor keep its threads.
+type VirtualThreads struct {
+	Logger bard.Logger
+}
+
+func (v VirtualThreads) Execute() (map[string]string, error) {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("BPL_TOMCAT_VIRTUAL_THREADS")))
+	switch mode {
+	case "", VirtualThreadsDisabled:
+		return nil, nil
+	case VirtualThreadsEnabled, VirtualThreadsRequired:
+	default:
+		return nil, fmt.Errorf("unknown $BPL_TOMCAT_VIRTUAL_THREADS %q, must be %s, %s or %s",
+			mode, VirtualThreadsDisabled, VirtualThreadsEnabled, VirtualThreadsRequired)
+	}
+
+	if reason := v.unsupported(); reason != "" {
+		if mode == VirtualThreadsRequired {
+			return nil, fmt.Errorf("unable to use virtual threads, %s", reason)
+		}
+		v.Logger.Infof("WARNING: not using virtual threads, %s", reason)
+		return nil, nil
+	}
+
+	property := fmt.Sprintf("-D%s=true", VirtualThreadsProperty)
+	v.Logger.Infof("Tomcat processing requests on virtual threads: %s", property)
+
+	var values []string
+	if s, ok := os.LookupEnv("JAVA_TOOL_OPTIONS"); ok {
+		values = append(values, s)
+	}
+	values = append(values, property)
+
+	return map[string]string{"JAVA_TOOL_OPTIONS": strings.Join(values, " ")}, nil
+}
+
+func (VirtualThreads) unsupported() string {
+	tomcat, ok := os.LookupEnv("BPI_TOMCAT_VERSION")
+	if !ok {
+		return "the Tomcat version is unknown"
+	}
+	t, err := semver.NewVersion(tomcat)
+	if err != nil {
+		return fmt.Sprintf("unable to parse Tomcat version %q", tomcat)
+	}
+	if t.LessThan(semver.MustParse(VirtualThreadsMinimumTomcat)) {
+		return fmt.Sprintf("Tomcat %s does not support them, %s or later is required", tomcat, VirtualThreadsMinimumTomcat)
+	}
+
+	java, err := JavaVersion()
+	if err != nil {
+		return err.Error()
+	}
+	major, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(java, "1."), ".", 2)[0])
+	if err != nil {
+		return fmt.Sprintf("unable to parse Java version %q", java)
+	}
+	if major < VirtualThreadsMinimumJava {
+		return fmt.Sprintf("Java %s does not support them, %d or later is required", java, VirtualThreadsMinimumJava)
+	}
+
+	return ""
+}
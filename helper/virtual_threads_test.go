@@ -0,0 +1,106 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package helper_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/helper"
+)
+
+func testVirtualThreads(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		javaHome string
+		v        = helper.VirtualThreads{}
+	)
+
+	it.Before(func() {
+		var err error
+		javaHome, err = os.MkdirTemp("", "java-home")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(javaHome, "release"), []byte("IMPLEMENTOR=\"BellSoft\"\nJAVA_VERSION=\"21.0.4\"\n"), 0644)).To(Succeed())
+		t.Setenv("JAVA_HOME", javaHome)
+
+		t.Setenv("BPI_TOMCAT_VERSION", "11.0.2")
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(javaHome)).To(Succeed())
+	})
+
+	it("does not contribute if not configured", func() {
+		Expect(v.Execute()).To(BeNil())
+	})
+
+	it("configures virtual threads", func() {
+		t.Setenv("BPL_TOMCAT_VIRTUAL_THREADS", "true")
+
+		Expect(v.Execute()).To(Equal(map[string]string{
+			"JAVA_TOOL_OPTIONS": "-Dtomcat.connector.useVirtualThreads=true",
+		}))
+	})
+
+	it("appends to $JAVA_TOOL_OPTIONS", func() {
+		t.Setenv("BPL_TOMCAT_VIRTUAL_THREADS", "required")
+		t.Setenv("JAVA_TOOL_OPTIONS", "-Xss1M")
+
+		Expect(v.Execute()).To(Equal(map[string]string{
+			"JAVA_TOOL_OPTIONS": "-Xss1M -Dtomcat.connector.useVirtualThreads=true",
+		}))
+	})
+
+	it("reads the Java version", func() {
+		Expect(helper.JavaVersion()).To(Equal("21.0.4"))
+	})
+
+	it("warns if Java does not support virtual threads", func() {
+		t.Setenv("BPL_TOMCAT_VIRTUAL_THREADS", "true")
+		Expect(os.WriteFile(filepath.Join(javaHome, "release"), []byte("JAVA_VERSION=\"17.0.12\"\n"), 0644)).To(Succeed())
+
+		Expect(v.Execute()).To(BeNil())
+	})
+
+	it("fails if required and Tomcat does not support virtual threads", func() {
+		t.Setenv("BPL_TOMCAT_VIRTUAL_THREADS", "required")
+		t.Setenv("BPI_TOMCAT_VERSION", "9.0.98")
+
+		_, err := v.Execute()
+		Expect(err).To(MatchError("unable to use virtual threads, Tomcat 9.0.98 does not support them, 10.1 or later is required"))
+	})
+
+	it("fails if required and the Java version is unknown", func() {
+		t.Setenv("BPL_TOMCAT_VIRTUAL_THREADS", "required")
+		Expect(os.Remove(filepath.Join(javaHome, "release"))).To(Succeed())
+
+		_, err := v.Execute()
+		Expect(err).To(MatchError(ContainSubstring("unable to use virtual threads, the Java version is unknown")))
+	})
+
+	it("rejects unknown modes", func() {
+		t.Setenv("BPL_TOMCAT_VIRTUAL_THREADS", "sometimes")
+
+		_, err := v.Execute()
+		Expect(err).To(MatchError(`unknown $BPL_TOMCAT_VIRTUAL_THREADS "sometimes", must be false, true or required`))
+	})
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"os"
+	"strings"
+)
+
+// InsertAfter inserts text after the first occurrence of marker in a file.  It returns false, leaving the file
+// unchanged, if the marker is not found.
+func InsertAfter(fileName string, marker string, text string) (bool, error) {
+	return insert(fileName, marker, text, len(marker))
+}
+
+// InsertBefore inserts text before the first occurrence of marker in a file.  It returns false, leaving the file
+// unchanged, if the marker is not found.
+func InsertBefore(fileName string, marker string, text string) (bool, error) {
+	return insert(fileName, marker, text, 0)
+}
+
+func insert(fileName string, marker string, text string, offset int) (bool, error) {
+	input, err := os.ReadFile(fileName)
+	if err != nil {
+		return false, err
+	}
+
+	i := strings.Index(string(input), marker)
+	if i < 0 {
+		return false, nil
+	}
+	i += offset
+
+	output := string(input[:i]) + text + string(input[i:])
+	if err := os.WriteFile(fileName, []byte(output), 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Element returns a regular expression matching the start tags, including empty element tags, of the elements called
+// name.
+func Element(name string) *regexp.Regexp {
+	return regexp.MustCompile(`<` + regexp.QuoteMeta(name) + `\b[^>]*>`)
+}
+
+// HasAttribute returns whether a start tag sets the attribute name.
+func HasAttribute(element string, name string) bool {
+	return regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=`).MatchString(element)
+}
+
+// AddAttributes adds the attributes, name and value pairs, that a start tag does not already set after the element
+// name, leaving those it sets unchanged.
+func AddAttributes(element string, attributes [][2]string) string {
+	var missing []string
+	for _, a := range attributes {
+		if !HasAttribute(element, a[0]) {
+			missing = append(missing, fmt.Sprintf("%s='%s'", a[0], a[1]))
+		}
+	}
+	if len(missing) == 0 {
+		return element
+	}
+
+	i := strings.IndexAny(element[1:], " \t\r\n/>") + 1
+	return element[:i] + " " + strings.Join(missing, " ") + element[i:]
+}
+
+// Attribute returns the value of the attribute name of a start tag and whether the start tag sets it.
+func Attribute(element string, name string) (string, bool) {
+	m := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=\s*(?:'([^']*)'|"([^"]*)")`).FindStringSubmatch(element)
+	if m == nil {
+		return "", false
+	}
+	return m[1] + m[2], true
+}
+
+// SetAttribute replaces the value of the attribute name of a start tag, keeping its quotes, or adds the attribute after
+// the element name if the start tag does not set it.  value is written as is and must not contain the quote.
+func SetAttribute(element string, name string, value string) string {
+	attribute := regexp.MustCompile(`(\s` + regexp.QuoteMeta(name) + `\s*=\s*)(?:'[^']*'|"[^"]*")`)
+	m := attribute.FindStringSubmatchIndex(element)
+	if m == nil {
+		return AddAttributes(element, [][2]string{{name, value}})
+	}
+
+	quote := element[m[3] : m[3]+1]
+	return element[:m[3]] + quote + value + quote + element[m[1]:]
+}
+
+// RemoveElements removes the elements called name whose className attribute is className, along with their content,
+// their end tag and the indentation and line break before them.  Elements called name must not be nested.
+func RemoveElements(s string, name string, className string) string {
+	element := regexp.MustCompile(`(?:\r?\n[ \t]*)?<` + regexp.QuoteMeta(name) + `\b[^>]*>`)
+	end := regexp.MustCompile(`^(?s:.*?)</` + regexp.QuoteMeta(name) + `\s*>`)
+
+	var b strings.Builder
+	last := 0
+	for _, m := range element.FindAllStringIndex(s, -1) {
+		if m[0] < last {
+			continue
+		}
+
+		tag := s[m[0]:m[1]]
+		if v, ok := Attribute(tag, "className"); !ok || v != className {
+			continue
+		}
+
+		b.WriteString(s[last:m[0]])
+		last = m[1]
+		if !strings.HasSuffix(tag, "/>") {
+			if e := end.FindStringIndex(s[last:]); e != nil {
+				last += e[1]
+			}
+		}
+	}
+	b.WriteString(s[last:])
+
+	return b.String()
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver/v3"
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/bindings"
+)
+
+const BindingTypeTomcatAdvisories = "tomcat-advisories"
+
+var severities = map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// Advisory describes a known vulnerability in a range of versions of a dependency.
+type Advisory struct {
+	ID          string   `toml:"id"`
+	Dependency  string   `toml:"dependency"`
+	Severity    string   `toml:"severity"`
+	Affected    []string `toml:"affected"`
+	Fixed       []string `toml:"fixed"`
+	Description string   `toml:"description"`
+	URI         string   `toml:"uri"`
+}
+
+// Affects returns whether the version of a dependency is within any of the advisory's affected ranges.
+func (a Advisory) Affects(dependency libpak.BuildpackDependency) (bool, error) {
+	if a.Dependency != dependency.ID {
+		return false, nil
+	}
+
+	v, err := semver.NewVersion(dependency.Version)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse version %s of %s\n%w", dependency.Version, dependency.ID, err)
+	}
+
+	for _, r := range a.Affected {
+		c, err := semver.NewConstraint(r)
+		if err != nil {
+			return false, fmt.Errorf("invalid affected range %q in advisory %s\n%w", r, a.ID, err)
+		}
+		if c.Check(v) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// AdvisoryDatabase is an offline collection of advisories, stored as TOML.
+type AdvisoryDatabase struct {
+	Advisories []Advisory `toml:"advisories"`
+}
+
+// NewAdvisoryDatabase reads advisories from each file in $BP_TOMCAT_ADVISORIES_FILE, a list separated by the OS path
+// list separator, and from each entry of every binding of type tomcat-advisories.
+func NewAdvisoryDatabase(configurationResolver libpak.ConfigurationResolver, binds libcnb.Bindings) (AdvisoryDatabase, error) {
+	var db AdvisoryDatabase
+
+	if s, ok := configurationResolver.Resolve("BP_TOMCAT_ADVISORIES_FILE"); ok && s != "" {
+		for _, file := range strings.Split(s, string(os.PathListSeparator)) {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return AdvisoryDatabase{}, fmt.Errorf("unable to read %s\n%w", file, err)
+			}
+			if err := db.add(file, string(b)); err != nil {
+				return AdvisoryDatabase{}, err
+			}
+		}
+	}
+
+	for _, b := range bindings.Resolve(binds, bindings.OfType(BindingTypeTomcatAdvisories)) {
+		var keys []string
+		for k := range b.Secret {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if err := db.add(fmt.Sprintf("binding %s/%s", b.Name, k), b.Secret[k]); err != nil {
+				return AdvisoryDatabase{}, err
+			}
+		}
+	}
+
+	return db, nil
+}
+
+func (a *AdvisoryDatabase) add(source string, content string) error {
+	var db AdvisoryDatabase
+	if _, err := toml.Decode(content, &db); err != nil {
+		return fmt.Errorf("unable to decode advisories from %s\n%w", source, err)
+	}
+
+	for _, adv := range db.Advisories {
+		if _, ok := severities[strings.ToLower(adv.Severity)]; !ok {
+			return fmt.Errorf("invalid severity %q in advisory %s from %s", adv.Severity, adv.ID, source)
+		}
+	}
+
+	a.Advisories = append(a.Advisories, db.Advisories...)
+	return nil
+}
+
+// AdvisoryChecker reports the advisories that affect resolved dependencies.  Advisories with a severity at or above
+// FailSeverity cause the check to fail, all others are logged as warnings.
+type AdvisoryChecker struct {
+	Database     AdvisoryDatabase
+	FailSeverity string
+	Logger       bard.Logger
+}
+
+// NewAdvisoryChecker creates an AdvisoryChecker, rejecting a failSeverity other than none, low, medium, high or critical
+// whether or not the database has advisories.
+func NewAdvisoryChecker(database AdvisoryDatabase, failSeverity string, logger bard.Logger) (AdvisoryChecker, error) {
+	if _, err := severityThreshold(failSeverity); err != nil {
+		return AdvisoryChecker{}, err
+	}
+
+	return AdvisoryChecker{Database: database, FailSeverity: failSeverity, Logger: logger}, nil
+}
+
+func (a AdvisoryChecker) Check(dependencies ...libpak.BuildpackDependency) error {
+	threshold, err := severityThreshold(a.FailSeverity)
+	if err != nil {
+		return err
+	}
+
+	if len(a.Database.Advisories) == 0 {
+		return nil
+	}
+
+	a.Logger.Header(color.BlueString("Checking %d advisories", len(a.Database.Advisories)))
+
+	var failures []string
+	for _, dep := range dependencies {
+		for _, adv := range a.Database.Advisories {
+			affected, err := adv.Affects(dep)
+			if err != nil {
+				return err
+			}
+			if !affected {
+				continue
+			}
+
+			s := fmt.Sprintf("%s %s is affected by %s (%s)", dep.Name, dep.Version, adv.ID, strings.ToLower(adv.Severity))
+			if len(adv.Fixed) > 0 {
+				s = fmt.Sprintf("%s, fixed in %s", s, strings.Join(adv.Fixed, ", "))
+			}
+			if adv.URI != "" {
+				s = fmt.Sprintf("%s: %s", s, adv.URI)
+			}
+
+			if threshold > 0 && severities[strings.ToLower(adv.Severity)] >= threshold {
+				a.Logger.Body(color.RedString("ERROR: %s", s))
+				failures = append(failures, s)
+			} else {
+				a.Logger.Body(color.YellowString("WARNING: %s", s))
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("dependencies are affected by advisories at or above severity %s\n%s",
+			strings.ToLower(a.FailSeverity), strings.Join(failures, "\n"))
+	}
+
+	return nil
+}
+
+// severityThreshold returns the rank of a fail severity, zero for none or the empty string.
+func severityThreshold(severity string) (int, error) {
+	if severity == "" || strings.ToLower(severity) == "none" {
+		return 0, nil
+	}
+
+	threshold, ok := severities[strings.ToLower(severity)]
+	if !ok {
+		return 0, fmt.Errorf("invalid severity threshold %q, must be one of none, low, medium, high or critical", severity)
+	}
+	return threshold, nil
+}
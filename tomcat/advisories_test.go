@@ -0,0 +1,156 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testAdvisories(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = os.MkdirTemp("", "advisories")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(filepath.Join(path, "advisories.toml"), []byte(`
+[[advisories]]
+  id = "TEST-0001"
+  dependency = "tomcat"
+  severity = "high"
+  affected = [">=9.0.0, <9.0.100", ">=10.1.0, <10.1.40"]
+  fixed = ["9.0.100", "10.1.40"]
+  uri = "https://example.com/TEST-0001"
+
+[[advisories]]
+  id = "TEST-0002"
+  dependency = "tomcat-logging-support"
+  severity = "low"
+  affected = ["<4.0.0"]
+`), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("reads advisories from $BP_TOMCAT_ADVISORIES_FILE and bindings", func() {
+		t.Setenv("BP_TOMCAT_ADVISORIES_FILE", filepath.Join(path, "advisories.toml"))
+
+		db, err := tomcat.NewAdvisoryDatabase(libpak.ConfigurationResolver{}, libcnb.Bindings{
+			{
+				Name: "test-binding",
+				Type: "tomcat-advisories",
+				Secret: map[string]string{"extra.toml": `
+[[advisories]]
+  id = "TEST-0003"
+  dependency = "tomcat"
+  severity = "critical"
+  affected = ["11.0.0"]
+`},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(db.Advisories).To(HaveLen(3))
+		Expect(db.Advisories[0].ID).To(Equal("TEST-0001"))
+		Expect(db.Advisories[2].ID).To(Equal("TEST-0003"))
+	})
+
+	it("rejects invalid severities", func() {
+		_, err := tomcat.NewAdvisoryDatabase(libpak.ConfigurationResolver{}, libcnb.Bindings{
+			{
+				Name:   "test-binding",
+				Type:   "tomcat-advisories",
+				Secret: map[string]string{"extra.toml": "[[advisories]]\nid = \"TEST\"\nseverity = \"severe\"\n"},
+			},
+		})
+		Expect(err).To(MatchError(ContainSubstring(`invalid severity "severe" in advisory TEST`)))
+	})
+
+	context("AdvisoryChecker", func() {
+		var db tomcat.AdvisoryDatabase
+
+		it.Before(func() {
+			t.Setenv("BP_TOMCAT_ADVISORIES_FILE", filepath.Join(path, "advisories.toml"))
+
+			var err error
+			db, err = tomcat.NewAdvisoryDatabase(libpak.ConfigurationResolver{}, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("warns without a threshold", func() {
+			a := tomcat.AdvisoryChecker{Database: db}
+
+			Expect(a.Check(
+				libpak.BuildpackDependency{ID: "tomcat", Name: "Apache Tomcat", Version: "9.0.99"},
+				libpak.BuildpackDependency{ID: "tomcat-logging-support", Version: "3.4.0"},
+			)).To(Succeed())
+		})
+
+		it("fails at or above the threshold", func() {
+			a := tomcat.AdvisoryChecker{Database: db, FailSeverity: "high"}
+
+			Expect(a.Check(libpak.BuildpackDependency{ID: "tomcat", Name: "Apache Tomcat", Version: "10.1.39"})).
+				To(MatchError(ContainSubstring("Apache Tomcat 10.1.39 is affected by TEST-0001 (high), fixed in 9.0.100, 10.1.40: https://example.com/TEST-0001")))
+		})
+
+		it("passes below the threshold", func() {
+			a := tomcat.AdvisoryChecker{Database: db, FailSeverity: "critical"}
+
+			Expect(a.Check(libpak.BuildpackDependency{ID: "tomcat", Name: "Apache Tomcat", Version: "10.1.39"})).To(Succeed())
+		})
+
+		it("passes unaffected versions", func() {
+			a := tomcat.AdvisoryChecker{Database: db, FailSeverity: "low"}
+
+			Expect(a.Check(
+				libpak.BuildpackDependency{ID: "tomcat", Name: "Apache Tomcat", Version: "10.1.40"},
+				libpak.BuildpackDependency{ID: "tomcat-logging-support", Version: "4.0.0"},
+			)).To(Succeed())
+		})
+
+		it("rejects an invalid threshold", func() {
+			a := tomcat.AdvisoryChecker{Database: db, FailSeverity: "severe"}
+
+			Expect(a.Check()).To(MatchError(ContainSubstring(`invalid severity threshold "severe"`)))
+		})
+
+		it("rejects an invalid threshold without advisories", func() {
+			_, err := tomcat.NewAdvisoryChecker(tomcat.AdvisoryDatabase{}, "severe", bard.NewLogger(io.Discard))
+
+			Expect(err).To(MatchError(ContainSubstring(`invalid severity threshold "severe"`)))
+		})
+	})
+}
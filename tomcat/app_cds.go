@@ -0,0 +1,255 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+const (
+	// AppCDSArchive is the name of the class data sharing archive in the app-cds layer.
+	AppCDSArchive = "tomcat.jsa"
+
+	// AppCDSMinimumJava is the first Java version that writes dynamic archives with -XX:ArchiveClassesAtExit.
+	AppCDSMinimumJava = 13
+
+	// DefaultAppCDSTimeout is how long the training run may take to start Tomcat and write the archive.
+	DefaultAppCDSTimeout = 5 * time.Minute
+
+	// TomcatStartedMessage is logged by Tomcat once it has started and deployed the webapps.
+	TomcatStartedMessage = "Server startup in"
+)
+
+// trainingDirectories are the directories of $CATALINA_BASE that Tomcat writes to while it runs.
+var trainingDirectories = []string{"logs", "temp", "webapps", "work"}
+
+// AppCDS contributes a dynamic AppCDS archive of the classes Tomcat loads while it starts and deploys the webapps.  The
+// archive is created by a training run at build time that starts Tomcat with the JRE in $JAVA_HOME through the same
+// catalina.sh, $CATALINA_OPTS and class path as at launch, and stops it once it has started.  The app-cds helper
+// uses the archive at launch if the JRE matches the one it was created with.
+type AppCDS struct {
+	CatalinaBase     string
+	CatalinaHome     string
+	CatalinaOpts     string
+	JavaHome         string
+	JavaVersion      string
+	LayerContributor libpak.LayerContributor
+	Logger           bard.Logger
+	Timeout          time.Duration
+}
+
+// NewAppCDS creates an AppCDS that is recreated when the application, the JRE in javaHome or the catalina-base
+// metadata change.
+func NewAppCDS(applicationPath string, javaHome string, base Base, catalinaHome string, catalinaBase string) (AppCDS, error) {
+	application, err := sherpa.NewFileListingHash(applicationPath)
+	if err != nil {
+		return AppCDS{}, fmt.Errorf("unable to hash application\n%w", err)
+	}
+
+	javaVersion, err := JavaRuntimeVersion(javaHome)
+	if err != nil {
+		return AppCDS{}, err
+	}
+
+	return AppCDS{
+		CatalinaBase: catalinaBase,
+		CatalinaHome: catalinaHome,
+		CatalinaOpts: base.CatalinaOpts(),
+		JavaHome:     javaHome,
+		JavaVersion:  javaVersion,
+		LayerContributor: libpak.NewLayerContributor("Apache Tomcat AppCDS Archive", map[string]interface{}{
+			"application":   application,
+			"catalina-base": base.LayerContributor.ExpectedMetadata,
+			"java-version":  javaVersion,
+		}, libcnb.LayerTypes{
+			Launch: true,
+		}),
+		Timeout: DefaultAppCDSTimeout,
+	}, nil
+}
+
+func (a AppCDS) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	a.LayerContributor.Logger = a.Logger
+
+	return a.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		archive := filepath.Join(layer.Path, AppCDSArchive)
+
+		a.Logger.Header(color.BlueString("Training run with Java %s", a.JavaVersion))
+		existing, err := a.trainingEntries()
+		if err != nil {
+			return libcnb.Layer{}, err
+		}
+		trainErr := a.train(archive)
+		if err := a.removeTrainingEntries(existing); err != nil {
+			return libcnb.Layer{}, err
+		}
+		if trainErr != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to create AppCDS archive\n%w", trainErr)
+		}
+
+		if _, err := os.Stat(archive); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("training run did not create AppCDS archive %s\n%w", archive, err)
+		}
+		a.Logger.Bodyf("Created %s", archive)
+
+		layer.LaunchEnvironment.Default("BPI_TOMCAT_APPCDS_ARCHIVE", archive)
+		layer.LaunchEnvironment.Default("BPI_TOMCAT_APPCDS_JAVA_VERSION", a.JavaVersion)
+
+		return layer, nil
+	})
+}
+
+func (a AppCDS) train(archive string) error {
+	cmd := exec.Command("sh", filepath.Join(a.CatalinaHome, "bin", "catalina.sh"), "run")
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("CATALINA_BASE=%s", a.CatalinaBase),
+		fmt.Sprintf("CATALINA_HOME=%s", a.CatalinaHome),
+		fmt.Sprintf("CATALINA_OPTS=%s", a.CatalinaOpts),
+		"CATALINA_TMPDIR=/tmp",
+		fmt.Sprintf("JAVA_HOME=%s", a.JavaHome),
+		fmt.Sprintf("JAVA_OPTS=-XX:ArchiveClassesAtExit=%s", archive),
+	)
+
+	out, in := io.Pipe()
+	cmd.Stdout = in
+	cmd.Stderr = in
+
+	started := make(chan struct{})
+	var once sync.Once
+	go func() {
+		s := bufio.NewScanner(out)
+		for s.Scan() {
+			a.Logger.Body(s.Text())
+			if strings.Contains(s.Text(), TomcatStartedMessage) {
+				once.Do(func() { close(started) })
+			}
+		}
+		_, _ = io.Copy(io.Discard, out)
+	}()
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("unable to start Tomcat\n%w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		_ = in.Close()
+		exited <- err
+	}()
+
+	select {
+	case <-started:
+		a.Logger.Body("Stopping Tomcat")
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			return fmt.Errorf("unable to stop Tomcat\n%w", err)
+		}
+	case err := <-exited:
+		return fmt.Errorf("Tomcat exited before it started\n%w", err)
+	case <-time.After(a.Timeout):
+		_ = cmd.Process.Kill()
+		<-exited
+		return fmt.Errorf("Tomcat did not start within %s", a.Timeout)
+	}
+
+	select {
+	case err := <-exited:
+		// the JVM exits with 128 plus the signal number when SIGTERM stops it, Contribute checks the archive was written
+		var exit *exec.ExitError
+		if err != nil && !(errors.As(err, &exit) && exit.ExitCode() == 128+int(syscall.SIGTERM)) {
+			return fmt.Errorf("Tomcat did not stop cleanly\n%w", err)
+		}
+	case <-time.After(a.Timeout):
+		_ = cmd.Process.Kill()
+		<-exited
+		return fmt.Errorf("Tomcat did not stop within %s", a.Timeout)
+	}
+
+	return nil
+}
+
+// trainingEntries returns the entries of the directories of $CATALINA_BASE that Tomcat writes to, such as its logs,
+// work files and exploded WARs, and the directories themselves if they exist.
+func (a AppCDS) trainingEntries() (map[string]bool, error) {
+	entries := map[string]bool{}
+
+	for _, d := range trainingDirectories {
+		dir := filepath.Join(a.CatalinaBase, d)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to stat %s\n%w", dir, err)
+		}
+		entries[dir] = true
+
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list %s\n%w", dir, err)
+		}
+		for _, f := range files {
+			entries[filepath.Join(dir, f.Name())] = true
+		}
+	}
+
+	return entries, nil
+}
+
+// removeTrainingEntries removes the entries the training run created in the directories of $CATALINA_BASE that Tomcat
+// writes to, so the catalina-base layer is the same as without AppCDS.
+func (a AppCDS) removeTrainingEntries(existing map[string]bool) error {
+	after, err := a.trainingEntries()
+	if err != nil {
+		return err
+	}
+
+	for e := range after {
+		if existing[e] {
+			continue
+		}
+		if err := os.RemoveAll(e); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", e, err)
+		}
+	}
+
+	return nil
+}
+
+func (AppCDS) Name() string {
+	return "app-cds"
+}
+
+// JavaRuntimeVersion returns the JAVA_RUNTIME_VERSION, which identifies the build of a JRE, or failing that the
+// JAVA_VERSION from the release file of the JRE in javaHome.
+func JavaRuntimeVersion(javaHome string) (string, error) {
+	return javaRelease(javaHome, "JAVA_RUNTIME_VERSION", "JAVA_VERSION")
+}
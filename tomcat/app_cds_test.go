@@ -0,0 +1,168 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testAppCDS(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx          libcnb.BuildContext
+		catalinaHome string
+		javaHome     string
+	)
+
+	// catalina.sh stand-in that starts, writes logs, work files and an exploded WAR, writes the archive named in
+	// $JAVA_OPTS when it is stopped, exiting with 143 as the JVM does on SIGTERM, and records the environment it was
+	// started with
+	const started = `archive="${JAVA_OPTS#-XX:ArchiveClassesAtExit=}"
+mkdir -p "${CATALINA_BASE}/logs" "${CATALINA_BASE}/work/Catalina" "${CATALINA_BASE}/webapps/ROOT"
+trap 'echo "${CATALINA_BASE} ${CATALINA_OPTS}" > "${archive}"; exit 143' TERM
+echo "Server startup in [42] milliseconds"
+while true; do sleep 0.1; done
+`
+
+	it.Before(func() {
+		var err error
+
+		ctx.Application.Path, err = os.MkdirTemp("", "app-cds-application")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+		ctx.Layers.Path, err = os.MkdirTemp("", "app-cds-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		catalinaHome = filepath.Join(ctx.Layers.Path, "tomcat")
+		Expect(os.MkdirAll(filepath.Join(catalinaHome, "bin"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(catalinaHome, "bin", "catalina.sh"), []byte(started), 0755)).To(Succeed())
+
+		javaHome, err = os.MkdirTemp("", "app-cds-java-home")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(javaHome, "release"),
+			[]byte("IMPLEMENTOR=\"BellSoft\"\nJAVA_RUNTIME_VERSION=\"21.0.4+9-LTS\"\nJAVA_VERSION=\"21.0.4\"\n"), 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Application.Path)).To(Succeed())
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+		Expect(os.RemoveAll(javaHome)).To(Succeed())
+	})
+
+	it("contributes AppCDS archive", func() {
+		catalinaBase := filepath.Join(ctx.Layers.Path, "catalina-base")
+		Expect(os.MkdirAll(filepath.Join(catalinaBase, "webapps"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(catalinaBase, "webapps", "ROOT.war"), []byte{}, 0644)).To(Succeed())
+		a, err := tomcat.NewAppCDS(ctx.Application.Path, javaHome, tomcat.Base{}, catalinaHome, catalinaBase)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.JavaVersion).To(Equal("21.0.4+9-LTS"))
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = a.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		archive := filepath.Join(layer.Path, "tomcat.jsa")
+		Expect(layer.Launch).To(BeTrue())
+		Expect(layer.Build).To(BeFalse())
+		Expect(os.ReadFile(archive)).To(Equal([]byte(catalinaBase + " " + a.CatalinaOpts + "\n")))
+		Expect(layer.LaunchEnvironment["BPI_TOMCAT_APPCDS_ARCHIVE.default"]).To(Equal(archive))
+		Expect(layer.LaunchEnvironment["BPI_TOMCAT_APPCDS_JAVA_VERSION.default"]).To(Equal("21.0.4+9-LTS"))
+
+		Expect(filepath.Join(catalinaBase, "logs")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(catalinaBase, "work")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(catalinaBase, "webapps", "ROOT")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(catalinaBase, "webapps", "ROOT.war")).To(BeARegularFile())
+	})
+
+	it("fails if Tomcat exits before it starts", func() {
+		Expect(os.WriteFile(filepath.Join(catalinaHome, "bin", "catalina.sh"), []byte("exit 1\n"), 0755)).To(Succeed())
+
+		a, err := tomcat.NewAppCDS(ctx.Application.Path, javaHome, tomcat.Base{}, catalinaHome, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = a.Contribute(layer)
+		Expect(err).To(MatchError(ContainSubstring("Tomcat exited before it started")))
+	})
+
+	it("fails if Tomcat does not stop cleanly", func() {
+		Expect(os.WriteFile(filepath.Join(catalinaHome, "bin", "catalina.sh"),
+			[]byte(strings.Replace(started, "exit 143", "exit 1", 1)), 0755)).To(Succeed())
+
+		a, err := tomcat.NewAppCDS(ctx.Application.Path, javaHome, tomcat.Base{}, catalinaHome,
+			filepath.Join(ctx.Layers.Path, "catalina-base"))
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = a.Contribute(layer)
+		Expect(err).To(MatchError(ContainSubstring("Tomcat did not stop cleanly")))
+	})
+
+	it("fails if Tomcat stops without writing the archive", func() {
+		Expect(os.WriteFile(filepath.Join(catalinaHome, "bin", "catalina.sh"),
+			[]byte(strings.Replace(started, `echo "${CATALINA_BASE} ${CATALINA_OPTS}" > "${archive}"; `, "", 1)), 0755)).To(Succeed())
+
+		a, err := tomcat.NewAppCDS(ctx.Application.Path, javaHome, tomcat.Base{}, catalinaHome,
+			filepath.Join(ctx.Layers.Path, "catalina-base"))
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = a.Contribute(layer)
+		Expect(err).To(MatchError(ContainSubstring("training run did not create AppCDS archive")))
+	})
+
+	it("fails if Tomcat does not start in time", func() {
+		Expect(os.WriteFile(filepath.Join(catalinaHome, "bin", "catalina.sh"),
+			[]byte("while true; do sleep 0.1; done\n"), 0755)).To(Succeed())
+
+		a, err := tomcat.NewAppCDS(ctx.Application.Path, javaHome, tomcat.Base{}, catalinaHome, "")
+		Expect(err).NotTo(HaveOccurred())
+		a.Timeout = 200 * time.Millisecond
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = a.Contribute(layer)
+		Expect(err).To(MatchError(ContainSubstring("Tomcat did not start within 200ms")))
+	})
+
+	it("falls back to JAVA_VERSION", func() {
+		Expect(os.WriteFile(filepath.Join(javaHome, "release"), []byte("JAVA_VERSION=\"17.0.12\"\n"), 0644)).To(Succeed())
+
+		Expect(tomcat.JavaRuntimeVersion(javaHome)).To(Equal("17.0.12"))
+	})
+}
@@ -0,0 +1,262 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/paketo-buildpacks/libjvm"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/crush"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+// ApplicationKind classifies the contents of the application directory.
+type ApplicationKind int
+
+const (
+	// ApplicationSource is an application with no recognisable webapp, typically source code.
+	ApplicationSource ApplicationKind = iota
+
+	// ApplicationExplodedWebapp is an application with WEB-INF at its root.
+	ApplicationExplodedWebapp
+
+	// ApplicationWarFiles is an application with WAR files at its root.
+	ApplicationWarFiles
+
+	// ApplicationNestedWebapps is an application whose immediate subdirectories contain WEB-INF.
+	ApplicationNestedWebapps
+
+	// ApplicationBuildOutputWarFiles is an application with WAR files in target/ or build/libs/.
+	ApplicationBuildOutputWarFiles
+
+	// ApplicationExecutableWar is an exploded WAR that also declares a Main-Class, such as a Spring Boot WAR.
+	ApplicationExecutableWar
+
+	// ApplicationMainClass is an application that declares a Main-Class and is not a webapp.
+	ApplicationMainClass
+
+	// ApplicationWebFragment is an exploded JAR that only contains META-INF/web-fragment.xml.
+	ApplicationWebFragment
+)
+
+func (a ApplicationKind) String() string {
+	return [...]string{
+		"source",
+		"exploded webapp",
+		"WAR files",
+		"nested webapps",
+		"build output WAR files",
+		"executable WAR",
+		"Main-Class application",
+		"web fragment JAR",
+	}[a]
+}
+
+// Deployable returns whether Tomcat can deploy this kind of application.
+func (a ApplicationKind) Deployable() bool {
+	switch a {
+	case ApplicationExplodedWebapp, ApplicationWarFiles, ApplicationNestedWebapps, ApplicationBuildOutputWarFiles:
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplicationLayout describes what was found in the application directory and why it was classified as it was.
+type ApplicationLayout struct {
+	Decisions []string
+	Kind      ApplicationKind
+	MainClass string
+	Path      string
+	WarFiles  []string
+	Webapps   []string
+}
+
+var buildOutputDirectories = []string{"target", filepath.Join("build", "libs")}
+
+// BuildOutputWebapps is the directory of the application that WAR files found in build output are exploded into.
+const BuildOutputWebapps = ".tomcat-webapps"
+
+func NewApplicationLayout(path string) (ApplicationLayout, error) {
+	a := ApplicationLayout{Path: path}
+
+	m, err := libjvm.NewManifest(path)
+	if err != nil {
+		return ApplicationLayout{}, fmt.Errorf("unable to read manifest\n%w", err)
+	}
+	a.MainClass, _ = m.Get("Main-Class")
+
+	if a.WarFiles, err = filepath.Glob(filepath.Join(path, "*.war")); err != nil {
+		return ApplicationLayout{}, fmt.Errorf("unable to list war files in %s\n%w", path, err)
+	}
+	if len(a.WarFiles) > 0 {
+		a.Kind = ApplicationWarFiles
+		a.decide("found %d WAR file(s) at the application root: %s", len(a.WarFiles), a.relative(a.WarFiles))
+		return a, nil
+	}
+
+	webInf, err := sherpa.DirExists(filepath.Join(path, "WEB-INF"))
+	if err != nil {
+		return ApplicationLayout{}, fmt.Errorf("unable to stat file %s\n%w", filepath.Join(path, "WEB-INF"), err)
+	}
+
+	if webInf && a.MainClass != "" {
+		a.Kind = ApplicationExecutableWar
+		a.Webapps = []string{path}
+		a.decide("found WEB-INF and Manifest attribute 'Main-Class' %s: executable WAR", a.MainClass)
+		return a, nil
+	} else if webInf {
+		a.Kind = ApplicationExplodedWebapp
+		a.Webapps = []string{path}
+		a.decide("found WEB-INF at the application root: exploded webapp")
+		return a, nil
+	} else if a.MainClass != "" {
+		a.Kind = ApplicationMainClass
+		a.decide("found Manifest attribute 'Main-Class' %s without WEB-INF: not a webapp", a.MainClass)
+		return a, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return ApplicationLayout{}, fmt.Errorf("unable to read directory %s\n%w", path, err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if ok, err := sherpa.DirExists(filepath.Join(path, e.Name(), "WEB-INF")); err != nil {
+			return ApplicationLayout{}, fmt.Errorf("unable to stat file %s\n%w", filepath.Join(path, e.Name(), "WEB-INF"), err)
+		} else if ok {
+			a.Webapps = append(a.Webapps, filepath.Join(path, e.Name()))
+		}
+	}
+	if len(a.Webapps) > 0 {
+		a.Kind = ApplicationNestedWebapps
+		a.decide("found WEB-INF in %d subdirectories: %s", len(a.Webapps), a.relative(a.Webapps))
+		return a, nil
+	}
+
+	for _, dir := range buildOutputDirectories {
+		w, err := filepath.Glob(filepath.Join(path, dir, "*.war"))
+		if err != nil {
+			return ApplicationLayout{}, fmt.Errorf("unable to list war files in %s\n%w", filepath.Join(path, dir), err)
+		}
+		a.WarFiles = append(a.WarFiles, w...)
+	}
+	if len(a.WarFiles) > 0 {
+		sort.Strings(a.WarFiles)
+		a.Kind = ApplicationBuildOutputWarFiles
+		a.decide("found %d WAR file(s) in build output: %s", len(a.WarFiles), a.relative(a.WarFiles))
+		return a, nil
+	}
+
+	if ok, err := sherpa.FileExists(filepath.Join(path, "META-INF", "web-fragment.xml")); err != nil {
+		return ApplicationLayout{}, fmt.Errorf("unable to stat file %s\n%w", filepath.Join(path, "META-INF", "web-fragment.xml"), err)
+	} else if ok {
+		a.Kind = ApplicationWebFragment
+		a.decide("found META-INF/web-fragment.xml without WEB-INF: a web fragment JAR must be packaged in a webapp's WEB-INF/lib")
+		return a, nil
+	}
+
+	a.Kind = ApplicationSource
+	a.decide("no WEB-INF, WAR files or Main-Class found: this is normal when building from source")
+	return a, nil
+}
+
+// ExplodeBuildOutput explodes the WAR files found in build output into BuildOutputWebapps, named after each file, so
+// that they are deployed from the application without changing the build output or the rest of the application.  The
+// returned layout lists the exploded webapps.
+func (a ApplicationLayout) ExplodeBuildOutput(logger bard.Logger) (ApplicationLayout, error) {
+	if a.Kind != ApplicationBuildOutputWarFiles {
+		return a, nil
+	}
+
+	root := filepath.Join(a.Path, BuildOutputWebapps)
+	if err := os.RemoveAll(root); err != nil {
+		return ApplicationLayout{}, fmt.Errorf("unable to remove %s\n%w", root, err)
+	}
+
+	a.Webapps = nil
+	for _, w := range a.WarFiles {
+		name := strings.TrimSuffix(filepath.Base(w), filepath.Ext(w))
+		webapp := filepath.Join(root, name)
+		for _, e := range a.Webapps {
+			if e == webapp {
+				return ApplicationLayout{}, fmt.Errorf("unable to deploy %s, a WAR file called %s.war was already found in build output", a.relative([]string{w}), name)
+			}
+		}
+
+		logger.Bodyf("Exploding %s into %s", a.relative([]string{w}), a.relative([]string{webapp}))
+		in, err := os.Open(w)
+		if err != nil {
+			return ApplicationLayout{}, fmt.Errorf("unable to open %s\n%w", w, err)
+		}
+		err = crush.Extract(in, webapp, 0)
+		_ = in.Close()
+		if err != nil {
+			return ApplicationLayout{}, fmt.Errorf("unable to extract %s\n%w", w, err)
+		}
+
+		a.Webapps = append(a.Webapps, webapp)
+	}
+
+	return a, nil
+}
+
+// Mount returns the directory Tomcat deploys the application from and whether it contains one webapp per
+// subdirectory, rather than being a single webapp mounted at the configured context path.
+func (a ApplicationLayout) Mount() (string, bool) {
+	switch a.Kind {
+	case ApplicationWarFiles, ApplicationNestedWebapps:
+		return a.Path, true
+	case ApplicationBuildOutputWarFiles:
+		if len(a.Webapps) == 1 {
+			return a.Webapps[0], false
+		}
+		return filepath.Join(a.Path, BuildOutputWebapps), true
+	default:
+		return a.Path, false
+	}
+}
+
+// Report logs the detection decisions.
+func (a ApplicationLayout) Report(logger bard.Logger) {
+	logger.Infof("Application detected as %s", a.Kind)
+	for _, d := range a.Decisions {
+		logger.Infof("  %s", d)
+	}
+}
+
+func (a *ApplicationLayout) decide(format string, args ...interface{}) {
+	a.Decisions = append(a.Decisions, fmt.Sprintf(format, args...))
+}
+
+func (a ApplicationLayout) relative(paths []string) string {
+	var s []string
+	for _, p := range paths {
+		if r, err := filepath.Rel(a.Path, p); err == nil {
+			p = r
+		}
+		s = append(s, p)
+	}
+	return strings.Join(s, ", ")
+}
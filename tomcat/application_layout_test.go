@@ -0,0 +1,229 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testApplicationLayout(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		path, err = os.MkdirTemp("", "application-layout")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	copyWar := func(name string, destination string) {
+		Expect(os.MkdirAll(filepath.Dir(destination), 0755)).To(Succeed())
+
+		in, err := os.Open(filepath.Join("testdata", "warfiles", name))
+		Expect(err).NotTo(HaveOccurred())
+		defer in.Close()
+
+		out, err := os.Create(destination)
+		Expect(err).NotTo(HaveOccurred())
+		defer out.Close()
+
+		_, err = io.Copy(out, in)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	it("detects source", func() {
+		Expect(os.WriteFile(filepath.Join(path, "pom.xml"), []byte{}, 0644)).To(Succeed())
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationSource))
+		Expect(a.Kind.Deployable()).To(BeFalse())
+	})
+
+	it("detects exploded webapp", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "WEB-INF"), 0755)).To(Succeed())
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationExplodedWebapp))
+		Expect(a.Webapps).To(Equal([]string{path}))
+	})
+
+	it("detects WAR files", func() {
+		copyWar("api.war", filepath.Join(path, "api.war"))
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationWarFiles))
+		Expect(a.WarFiles).To(Equal([]string{filepath.Join(path, "api.war")}))
+	})
+
+	it("detects nested webapps", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "api", "WEB-INF"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "ui", "WEB-INF"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "docs"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, ".hidden", "WEB-INF"), 0755)).To(Succeed())
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationNestedWebapps))
+		Expect(a.Webapps).To(Equal([]string{filepath.Join(path, "api"), filepath.Join(path, "ui")}))
+	})
+
+	it("detects build output WAR files", func() {
+		copyWar("api.war", filepath.Join(path, "target", "api.war"))
+		copyWar("ui.war", filepath.Join(path, "build", "libs", "ui.war"))
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationBuildOutputWarFiles))
+		Expect(a.WarFiles).To(Equal([]string{
+			filepath.Join(path, "build", "libs", "ui.war"),
+			filepath.Join(path, "target", "api.war"),
+		}))
+	})
+
+	it("detects executable WAR", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "WEB-INF"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "META-INF", "MANIFEST.MF"),
+			[]byte("Main-Class: org.springframework.boot.loader.WarLauncher"), 0644)).To(Succeed())
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationExecutableWar))
+		Expect(a.MainClass).To(Equal("org.springframework.boot.loader.WarLauncher"))
+		Expect(a.Kind.Deployable()).To(BeFalse())
+	})
+
+	it("detects Main-Class application", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "META-INF", "MANIFEST.MF"), []byte("Main-Class: test-main-class"), 0644)).To(Succeed())
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationMainClass))
+	})
+
+	it("detects web fragment JAR", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "META-INF", "web-fragment.xml"), []byte("<web-fragment/>"), 0644)).To(Succeed())
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(a.Kind).To(Equal(tomcat.ApplicationWebFragment))
+		Expect(a.Kind.Deployable()).To(BeFalse())
+	})
+
+	it("reports decisions", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "WEB-INF"), 0755)).To(Succeed())
+
+		a, err := tomcat.NewApplicationLayout(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		b := &bytes.Buffer{}
+		a.Report(bard.NewLogger(b))
+		Expect(b.String()).To(ContainSubstring("Application detected as exploded webapp"))
+		Expect(b.String()).To(ContainSubstring("found WEB-INF at the application root"))
+	})
+
+	context("ExplodeBuildOutput", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(path, "src", "main", "webapp"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "pom.xml"), []byte{}, 0644)).To(Succeed())
+		})
+
+		it("explodes a single WAR file to be mounted at the context path", func() {
+			copyWar("api.war", filepath.Join(path, "target", "api.war"))
+
+			a, err := tomcat.NewApplicationLayout(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err = a.ExplodeBuildOutput(bard.NewLogger(io.Discard))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Kind).To(Equal(tomcat.ApplicationBuildOutputWarFiles))
+			Expect(a.Webapps).To(Equal([]string{filepath.Join(path, ".tomcat-webapps", "api")}))
+			Expect(filepath.Join(path, ".tomcat-webapps", "api", "WEB-INF")).To(BeADirectory())
+
+			mount, warFiles := a.Mount()
+			Expect(mount).To(Equal(filepath.Join(path, ".tomcat-webapps", "api")))
+			Expect(warFiles).To(BeFalse())
+
+			Expect(filepath.Join(path, "src", "main", "webapp")).To(BeADirectory())
+			Expect(filepath.Join(path, "pom.xml")).To(BeARegularFile())
+			Expect(filepath.Join(path, "target", "api.war")).To(BeARegularFile())
+		})
+
+		it("explodes multiple WAR files to be deployed at their names", func() {
+			copyWar("api.war", filepath.Join(path, "target", "api.war"))
+			copyWar("ui.war", filepath.Join(path, "build", "libs", "ui.war"))
+
+			a, err := tomcat.NewApplicationLayout(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err = a.ExplodeBuildOutput(bard.NewLogger(io.Discard))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(path, ".tomcat-webapps", "api", "WEB-INF")).To(BeADirectory())
+			Expect(filepath.Join(path, ".tomcat-webapps", "ui", "WEB-INF")).To(BeADirectory())
+
+			mount, warFiles := a.Mount()
+			Expect(mount).To(Equal(filepath.Join(path, ".tomcat-webapps")))
+			Expect(warFiles).To(BeTrue())
+
+			Expect(filepath.Join(path, "target", "api.war")).To(BeARegularFile())
+			Expect(filepath.Join(path, "build", "libs", "ui.war")).To(BeARegularFile())
+		})
+
+		it("rejects WAR files with the same name", func() {
+			copyWar("api.war", filepath.Join(path, "target", "api.war"))
+			copyWar("api.war", filepath.Join(path, "build", "libs", "api.war"))
+
+			a, err := tomcat.NewApplicationLayout(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = a.ExplodeBuildOutput(bard.NewLogger(io.Discard))
+			Expect(err).To(MatchError(ContainSubstring("a WAR file called api.war was already found in build output")))
+		})
+
+		it("does nothing for other layouts", func() {
+			a, err := tomcat.NewApplicationLayout(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			a, err = a.ExplodeBuildOutput(bard.NewLogger(io.Discard))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Kind).To(Equal(tomcat.ApplicationSource))
+			Expect(filepath.Join(path, ".tomcat-webapps")).NotTo(BeAnExistingFile())
+		})
+	})
+}
@@ -17,36 +17,38 @@
 package tomcat
 
 import (
-	"errors"
 	"fmt"
-	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
 	"os"
 	"path/filepath"
-	"strconv"
+	"sort"
 	"strings"
 
-	"github.com/paketo-buildpacks/libpak/sbom"
-
 	"github.com/buildpacks/libcnb"
 	"github.com/heroku/color"
 	"github.com/paketo-buildpacks/libpak"
 	"github.com/paketo-buildpacks/libpak/bard"
-	"github.com/paketo-buildpacks/libpak/crush"
 	"github.com/paketo-buildpacks/libpak/sherpa"
 )
 
 type Base struct {
-	AccessLoggingDependency         libpak.BuildpackDependency
+	AccessLoggingDependency         *libpak.BuildpackDependency
+	AdditionalContributors          []BaseContributor
 	ApplicationPath                 string
 	BuildpackPath                   string
 	ConfigurationResolver           libpak.ConfigurationResolver
 	ContextPath                     string
+	Contexts                        Contexts
 	DependencyCache                 libpak.DependencyCache
 	ExternalConfigurationDependency *libpak.BuildpackDependency
+	ExtraLibraries                  []libpak.BuildpackDependency
+	Hardening                       Hardening
 	LayerContributor                libpak.LayerContributor
-	LifecycleDependency             libpak.BuildpackDependency
-	LoggingDependency               libpak.BuildpackDependency
+	LifecycleDependency             *libpak.BuildpackDependency
+	LoggingDependency               *libpak.BuildpackDependency
 	Logger                          bard.Logger
+	Manager                         *Manager
+	OpenTelemetry                   *OpenTelemetry
+	StaticAssetConfiguration        StaticAssetConfiguration
 	WarFilesExist                   bool
 }
 
@@ -55,58 +57,92 @@ func NewBase(
 	buildpackPath string,
 	configurationResolver libpak.ConfigurationResolver,
 	contextPath string,
-	accessLoggingDependency libpak.BuildpackDependency,
+	accessLoggingDependency *libpak.BuildpackDependency,
 	externalConfigurationDependency *libpak.BuildpackDependency,
-	lifecycleDependency libpak.BuildpackDependency,
-	loggingDependency libpak.BuildpackDependency,
+	lifecycleDependency *libpak.BuildpackDependency,
+	loggingDependency *libpak.BuildpackDependency,
 	cache libpak.DependencyCache,
 	warFilesExist bool,
 ) (Base, []libcnb.BOMEntry) {
 
-	dependencies := []libpak.BuildpackDependency{accessLoggingDependency, lifecycleDependency, loggingDependency}
-	if externalConfigurationDependency != nil {
-		dependencies = append(dependencies, *externalConfigurationDependency)
+	var dependencies []libpak.BuildpackDependency
+	for _, d := range []*libpak.BuildpackDependency{accessLoggingDependency, lifecycleDependency, loggingDependency, externalConfigurationDependency} {
+		if d != nil {
+			dependencies = append(dependencies, *d)
+		}
 	}
 
+	externalConfigurationStrip, _ := configurationResolver.Resolve("BP_TOMCAT_EXT_CONF_STRIP")
+	hardening, _ := configurationResolver.Resolve("BP_TOMCAT_HARDENING")
+	sameSiteCookies, _ := configurationResolver.Resolve("BP_TOMCAT_HARDENING_SAME_SITE_COOKIES")
+	managerEnabled := configurationResolver.ResolveBool("BP_TOMCAT_MANAGER_ENABLED")
+	managerContextPath, _ := configurationResolver.Resolve("BP_TOMCAT_MANAGER_CONTEXT_PATH")
+	if managerContextPath == "" {
+		managerContextPath = DefaultManagerContextPath
+	}
+	rootPage, _ := configurationResolver.Resolve("BP_TOMCAT_ROOT_PAGE")
+	rootRedirect := configurationResolver.ResolveBool("BP_TOMCAT_ROOT_REDIRECT")
+	staticContexts, _ := configurationResolver.Resolve("BP_TOMCAT_STATIC_CONTEXTS")
+	staticPrecompression := configurationResolver.ResolveBool("BP_TOMCAT_STATIC_PRECOMPRESSION_ENABLED")
+
+	var staticCache []string
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, StaticCachePrefix) {
+			staticCache = append(staticCache, e)
+		}
+	}
+	sort.Strings(staticCache)
+
 	b := Base{
-		AccessLoggingDependency:         accessLoggingDependency,
-		ApplicationPath:                 applicationPath,
-		BuildpackPath:                   buildpackPath,
-		ConfigurationResolver:           configurationResolver,
-		ContextPath:                     contextPath,
+		AccessLoggingDependency: accessLoggingDependency,
+		ApplicationPath:         applicationPath,
+		BuildpackPath:           buildpackPath,
+		ConfigurationResolver:   configurationResolver,
+		ContextPath:             contextPath,
+		Contexts: Contexts{
+			ApplicationPath: applicationPath,
+			ContextName:     contextPath,
+			RootPage:        rootPage,
+			RootRedirect:    rootRedirect,
+		},
 		DependencyCache:                 cache,
 		ExternalConfigurationDependency: externalConfigurationDependency,
+		Hardening:                       Hardening{Profile: hardening, SameSiteCookies: sameSiteCookies},
 		LayerContributor: libpak.NewLayerContributor("Apache Tomcat Support", map[string]interface{}{
-			"context-path": contextPath,
-			"dependencies": dependencies,
+			"additional-jars":                      os.Getenv("BPI_TOMCAT_ADDITIONAL_JARS"),
+			"application-path":                     applicationPath,
+			"binding-property-source-enabled":      configurationResolver.ResolveBool("BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED"),
+			"configuration-validation-disabled":    configurationResolver.ResolveBool("BP_TOMCAT_CONFIGURATION_VALIDATION_DISABLED"),
+			"context-path":                         contextPath,
+			"dependencies":                         dependencies,
+			"environment-property-source-disabled": configurationResolver.ResolveBool("BP_TOMCAT_ENV_PROPERTY_SOURCE_DISABLED"),
+			"external-configuration-strip":         externalConfigurationStrip,
+			"hardening":                            hardening,
+			"hardening-same-site-cookies":          sameSiteCookies,
+			"manager-context-path":                 managerContextPath,
+			"manager-enabled":                      managerEnabled,
+			"root-page":                            rootPage,
+			"root-redirect":                        rootRedirect,
+			"static-cache":                         staticCache,
+			"static-contexts":                      staticContexts,
+			"static-precompression":                staticPrecompression,
+			"war-files-exist":                      warFilesExist,
 		}, libcnb.LayerTypes{
 			Launch: true,
 		}),
-		LifecycleDependency: lifecycleDependency,
-		LoggingDependency:   loggingDependency,
-		WarFilesExist:       warFilesExist,
+		LifecycleDependency:      lifecycleDependency,
+		LoggingDependency:        loggingDependency,
+		StaticAssetConfiguration: StaticAssetConfiguration{Precompressed: staticPrecompression},
+		WarFilesExist:            warFilesExist,
 	}
 
-	var bomEntries []libcnb.BOMEntry
-
-	var entry libcnb.BOMEntry
-	entry = accessLoggingDependency.AsBOMEntry()
-	entry.Metadata["layer"] = b.Name()
-	entry.Launch = true
-	bomEntries = append(bomEntries, entry)
-
-	entry = lifecycleDependency.AsBOMEntry()
-	entry.Metadata["layer"] = b.Name()
-	entry.Launch = true
-	bomEntries = append(bomEntries, entry)
-
-	entry = loggingDependency.AsBOMEntry()
-	entry.Metadata["layer"] = b.Name()
-	entry.Launch = true
-	bomEntries = append(bomEntries, entry)
+	if managerEnabled {
+		b.Manager = &Manager{ContextName: ContextName(managerContextPath)}
+	}
 
-	if externalConfigurationDependency != nil {
-		entry = externalConfigurationDependency.AsBOMEntry()
+	var bomEntries []libcnb.BOMEntry
+	for _, d := range dependencies {
+		entry := d.AsBOMEntry()
 		entry.Metadata["layer"] = b.Name()
 		entry.Launch = true
 		bomEntries = append(bomEntries, entry)
@@ -117,92 +153,33 @@ func NewBase(
 
 func (b Base) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	b.LayerContributor.Logger = b.Logger
-	var syftArtifacts []sbom.SyftArtifact
-
-	return b.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
-
-		if err := b.ContributeConfiguration(layer); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to contribute configuration\n%w", err)
-		}
-
-		if err := b.ContributeAccessLogging(layer); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to contribute access logging\n%w", err)
-		}
-		if syftArtifact, err := b.AccessLoggingDependency.AsSyftArtifact(); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to get Syft Artifact for dependency: %s, \n%w", b.AccessLoggingDependency.Name, err)
-		} else {
-			syftArtifacts = append(syftArtifacts, syftArtifact)
-		}
-
-		if err := b.ContributeLifecycle(layer); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to contribute lifecycle\n%w", err)
-		}
-		if syftArtifact, err := b.LifecycleDependency.AsSyftArtifact(); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to get Syft Artifact for dependency: %s, \n%w", b.LifecycleDependency.Name, err)
-		} else {
-			syftArtifacts = append(syftArtifacts, syftArtifact)
-		}
-
-		if err := b.ContributeLogging(layer); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to contribute logging\n%w", err)
-		}
-		if syftArtifact, err := b.LoggingDependency.AsSyftArtifact(); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to get Syft Artifact for dependency: %s, \n%w", b.LoggingDependency.Name, err)
-		} else {
-			syftArtifacts = append(syftArtifacts, syftArtifact)
-		}
 
-		if b.ExternalConfigurationDependency != nil {
-			if err := b.ContributeExternalConfiguration(layer); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to contribute external configuration\n%w", err)
-			}
-			if syftArtifact, err := b.ExternalConfigurationDependency.AsSyftArtifact(); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to get Syft Artifact for dependency: %s, \n%w", b.ExternalConfigurationDependency.Name, err)
-			} else {
-				syftArtifacts = append(syftArtifacts, syftArtifact)
-			}
-		}
-
-		if err := b.ContributeCatalinaProps(layer); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to contribute configuration\n%w", err)
+	resources, err := sherpa.NewFileListingHash(filepath.Join(b.BuildpackPath, "resources"))
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to hash resources\n%w", err)
+	}
+	if m, ok := b.LayerContributor.ExpectedMetadata.(map[string]interface{}); ok {
+		m["resources"] = resources
+		if len(b.ExtraLibraries) > 0 {
+			m["extra-libraries"] = b.ExtraLibraries
 		}
-
-		file := filepath.Join(layer.Path, "temp")
-		if err := os.MkdirAll(file, 0700); err != nil {
-			return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", file, err)
+		if b.OpenTelemetry != nil {
+			m["opentelemetry-agent"] = b.OpenTelemetry.Dependency
 		}
+	}
 
-		file = filepath.Join(layer.Path, "webapps")
-		if b.WarFilesExist {
-			if err := os.Symlink(b.ApplicationPath, file); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to create symlink from %s to %s\n%w", b.ApplicationPath, file, err)
-			}
-			if err := b.explodeWarFiles(); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to explode war files in %s\n%w", b.ApplicationPath, err)
-			}
-		} else {
-			if err := os.MkdirAll(file, 0755); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to create directory %s\n%w", file, err)
-			}
+	return b.LayerContributor.Contribute(layer, func() (libcnb.Layer, error) {
+		var dependencies []libpak.BuildpackDependency
 
-			file = filepath.Join(layer.Path, "webapps", b.ContextPath)
-			b.Logger.Headerf("Mounting application at %s", b.ContextPath)
-			if err := os.Symlink(b.ApplicationPath, file); err != nil {
-				return libcnb.Layer{}, fmt.Errorf("unable to create symlink from %s to %s\n%w", b.ApplicationPath, file, err)
+		for _, c := range b.Contributors() {
+			d, err := c.Contribute(layer)
+			if err != nil {
+				return libcnb.Layer{}, fmt.Errorf("unable to contribute %s\n%w", c.Name(), err)
 			}
+			dependencies = append(dependencies, d...)
 		}
 
-		catalinaOpts := "-DBPI_TOMCAT_ADDITIONAL_COMMON_JARS=${BPI_TOMCAT_ADDITIONAL_COMMON_JARS}"
-		environmentPropertySourceDisabled := b.ConfigurationResolver.ResolveBool("BP_TOMCAT_ENV_PROPERTY_SOURCE_DISABLED")
-		if !environmentPropertySourceDisabled {
-			catalinaOpts += " -Dorg.apache.tomcat.util.digester.PROPERTY_SOURCE=org.apache.tomcat.util.digester.EnvironmentPropertySource"
-		}
-		layer.LaunchEnvironment.Default("CATALINA_OPTS", catalinaOpts)
-
-		layer.LaunchEnvironment.Default("CATALINA_BASE", layer.Path)
-		layer.LaunchEnvironment.Default("CATALINA_TMPDIR", "/tmp")
-
-		if err := b.writeDependencySBOM(layer, syftArtifacts); err != nil {
+		if err := b.writeDependencySBOM(layer, dependencies); err != nil {
 			return libcnb.Layer{}, err
 		}
 
@@ -210,236 +187,222 @@ func (b Base) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	})
 }
 
-func (b Base) ContributeAccessLogging(layer libcnb.Layer) error {
-	b.Logger.Header(color.BlueString("%s %s", b.AccessLoggingDependency.Name, b.AccessLoggingDependency.Version))
+// Contributors returns the contributors that build $CATALINA_BASE, in order.  AdditionalContributors run once the
+// configuration is complete and before the webapps are contributed.
+func (b Base) Contributors() []BaseContributor {
+	contributors := []BaseContributor{Configuration{BuildpackPath: b.BuildpackPath, Logger: b.Logger}}
 
-	artifact, err := b.DependencyCache.Artifact(b.AccessLoggingDependency)
-	if err != nil {
-		return fmt.Errorf("unable to get dependency %s\n%w", b.AccessLoggingDependency.ID, err)
+	if b.AccessLoggingDependency != nil {
+		contributors = append(contributors, SupportLibrary{Dependency: *b.AccessLoggingDependency, DependencyCache: b.DependencyCache, Directory: "lib", Logger: b.Logger})
 	}
-	defer artifact.Close()
-
-	b.Logger.Bodyf("Copying to %s/lib", layer.Path)
-
-	file := filepath.Join(layer.Path, "lib", filepath.Base(b.AccessLoggingDependency.URI))
-	if err := sherpa.CopyFile(artifact, file); err != nil {
-		return fmt.Errorf("unable to copy %s to %s\n%w", filepath.Base(b.AccessLoggingDependency.URI), file, err)
+	if b.LifecycleDependency != nil {
+		contributors = append(contributors, SupportLibrary{Dependency: *b.LifecycleDependency, DependencyCache: b.DependencyCache, Directory: "lib", Logger: b.Logger})
 	}
-
-	return nil
-}
-
-func (b Base) ContributeConfiguration(layer libcnb.Layer) error {
-	file := filepath.Join(layer.Path, "conf")
-	if err := os.MkdirAll(file, 0755); err != nil {
-		return fmt.Errorf("unable to create directory %s\n%w", file, err)
+	if b.LoggingDependency != nil {
+		contributors = append(contributors, LoggingSupport{SupportLibrary{Dependency: *b.LoggingDependency, DependencyCache: b.DependencyCache, Logger: b.Logger}})
 	}
 
-	b.Logger.Bodyf("Copying context.xml to %s/conf", layer.Path)
-	file = filepath.Join(b.BuildpackPath, "resources", "context.xml")
-	in, err := os.Open(file)
-	if err != nil {
-		return fmt.Errorf("unable to open %s\n%w", file, err)
-	}
-	defer in.Close()
+	contributors = append(contributors, SupportComponents{
+		AccessLogging: b.AccessLoggingDependency != nil,
+		Lifecycle:     b.LifecycleDependency != nil,
+		Logger:        b.Logger,
+		Logging:       b.LoggingDependency != nil,
+	})
 
-	file = filepath.Join(layer.Path, "conf", "context.xml")
-	if err := sherpa.CopyFile(in, file); err != nil {
-		return fmt.Errorf("unable to copy %s to %s\n%w", in.Name(), file, err)
+	for _, d := range b.ExtraLibraries {
+		contributors = append(contributors, SupportLibrary{Dependency: d, DependencyCache: b.DependencyCache, Directory: "lib", Logger: b.Logger})
 	}
 
-	b.Logger.Bodyf("Copying logging.properties to %s/conf", layer.Path)
-	file = filepath.Join(b.BuildpackPath, "resources", "logging.properties")
-	in, err = os.Open(file)
-	if err != nil {
-		return fmt.Errorf("unable to open %s\n%w", file, err)
+	if b.ExternalConfigurationDependency != nil {
+		strip, _ := b.ConfigurationResolver.Resolve("BP_TOMCAT_EXT_CONF_STRIP")
+		contributors = append(contributors, ExternalConfiguration{
+			Dependency:      *b.ExternalConfigurationDependency,
+			DependencyCache: b.DependencyCache,
+			Logger:          b.Logger,
+			Strip:           strip,
+		})
 	}
-	defer in.Close()
 
-	file = filepath.Join(layer.Path, "conf", "logging.properties")
-	if err := sherpa.CopyFile(in, file); err != nil {
-		return fmt.Errorf("unable to copy %s to %s\n%w", in.Name(), file, err)
-	}
+	contributors = append(contributors,
+		CatalinaProperties{Logger: b.Logger},
+		BaseContributorFunc{ContributorName: "static asset configuration", Func: b.ContributeStaticAssetConfiguration},
+		BaseContributorFunc{ContributorName: "hardening", Func: b.ContributeHardening},
+		ConnectorSizing{Logger: b.Logger},
+		RemoteIP{Logger: b.Logger},
+	)
 
-	b.Logger.Bodyf("Copying server.xml to %s/conf", layer.Path)
-	file = filepath.Join(b.BuildpackPath, "resources", "server.xml")
-	in, err = os.Open(file)
-	if err != nil {
-		return fmt.Errorf("unable to open %s\n%w", file, err)
+	if b.OpenTelemetry != nil {
+		contributors = append(contributors, BaseContributorFunc{ContributorName: "OpenTelemetry", Func: b.ContributeOpenTelemetry})
 	}
-	defer in.Close()
 
-	file = filepath.Join(layer.Path, "conf", "server.xml")
-	if err := sherpa.CopyFile(in, file); err != nil {
-		return fmt.Errorf("unable to copy %s to %s\n%w", in.Name(), file, err)
-	}
+	contributors = append(contributors, b.AdditionalContributors...)
 
-	b.Logger.Bodyf("Copying web.xml to %s/conf", layer.Path)
-	file = filepath.Join(b.BuildpackPath, "resources", "web.xml")
-	in, err = os.Open(file)
-	if err != nil {
-		return fmt.Errorf("unable to open %s\n%w", file, err)
-	}
-	defer in.Close()
+	contributors = append(contributors, BaseContributorFunc{ContributorName: "webapps", Func: b.ContributeWebapps})
 
-	file = filepath.Join(layer.Path, "conf", "web.xml")
-	if err := sherpa.CopyFile(in, file); err != nil {
-		return fmt.Errorf("unable to copy %s to %s\n%w", in.Name(), file, err)
+	if b.Manager != nil {
+		contributors = append(contributors, BaseContributorFunc{ContributorName: "manager", Func: b.ContributeManager})
 	}
 
-	return nil
+	return append(contributors,
+		BaseContributorFunc{ContributorName: "environment", Func: b.ContributeEnvironment},
+		BaseContributorFunc{ContributorName: "configuration validation", Func: b.ValidateConfiguration},
+	)
 }
 
-func (b Base) ContributeExternalConfiguration(layer libcnb.Layer) error {
-	b.Logger.Header(color.BlueString("%s %s", b.ExternalConfigurationDependency.Name, b.ExternalConfigurationDependency.Version))
+func (b Base) ContributeEnvironment(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	layer.LaunchEnvironment.Default("CATALINA_OPTS", b.CatalinaOpts())
 
-	artifact, err := b.DependencyCache.Artifact(*b.ExternalConfigurationDependency)
-	if err != nil {
-		return fmt.Errorf("unable to get dependency %s\n%w", b.ExternalConfigurationDependency.ID, err)
-	}
-	defer artifact.Close()
+	layer.LaunchEnvironment.Default("CATALINA_BASE", layer.Path)
+	layer.LaunchEnvironment.Default("CATALINA_TMPDIR", "/tmp")
 
-	b.Logger.Bodyf("Expanding to %s", layer.Path)
+	return nil, nil
+}
 
-	c := 0
-	if s, ok := b.ConfigurationResolver.Resolve("BP_TOMCAT_EXT_CONF_STRIP"); ok {
-		if c, err = strconv.Atoi(s); err != nil {
-			return fmt.Errorf("unable to parse %s to integer\n%w", s, err)
-		}
+// CatalinaOpts returns the $CATALINA_OPTS Tomcat is launched with.
+func (b Base) CatalinaOpts() string {
+	catalinaOpts := "-DBPI_TOMCAT_ADDITIONAL_COMMON_JARS=${BPI_TOMCAT_ADDITIONAL_COMMON_JARS}"
+	var propertySources []string
+	if b.ConfigurationResolver.ResolveBool("BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED") {
+		propertySources = append(propertySources, "org.apache.tomcat.util.digester.ServiceBindingPropertySource")
 	}
-
-	if err := crush.ExtractTarGz(artifact, layer.Path, c); err != nil {
-		return fmt.Errorf("unable to expand external configuration\n%w", err)
+	environmentPropertySourceDisabled := b.ConfigurationResolver.ResolveBool("BP_TOMCAT_ENV_PROPERTY_SOURCE_DISABLED")
+	if !environmentPropertySourceDisabled {
+		propertySources = append(propertySources, "org.apache.tomcat.util.digester.EnvironmentPropertySource")
+	}
+	if len(propertySources) > 0 {
+		catalinaOpts += " -Dorg.apache.tomcat.util.digester.PROPERTY_SOURCE=" + strings.Join(propertySources, ",")
 	}
 
-	return nil
+	return catalinaOpts
 }
 
-func (b Base) ContributeLifecycle(layer libcnb.Layer) error {
-	b.Logger.Header(color.BlueString("%s %s", b.LifecycleDependency.Name, b.LifecycleDependency.Version))
+func (b Base) ContributeManager(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	m := *b.Manager
+	m.Logger = b.Logger
+	return nil, m.Contribute(layer.Path)
+}
+
+func (b Base) ContributeOpenTelemetry(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	o := *b.OpenTelemetry
+	o.DependencyCache = b.DependencyCache
+	o.Logger = b.Logger
 
-	artifact, err := b.DependencyCache.Artifact(b.LifecycleDependency)
+	agent, err := o.Contribute(layer.Path)
 	if err != nil {
-		return fmt.Errorf("unable to get dependency %s\n%w", b.LifecycleDependency.ID, err)
+		return nil, err
 	}
-	defer artifact.Close()
+	layer.LaunchEnvironment.Default("BPI_TOMCAT_OTEL_AGENT", agent)
 
-	b.Logger.Bodyf("Copying to %s/lib", layer.Path)
+	return []libpak.BuildpackDependency{o.Dependency}, nil
+}
 
-	file := filepath.Join(layer.Path, "lib", filepath.Base(b.LifecycleDependency.URI))
-	if err := sherpa.CopyFile(artifact, file); err != nil {
-		return fmt.Errorf("unable to copy %s to %s\n%w", filepath.Base(b.LifecycleDependency.URI), file, err)
+func (b Base) ContributeWebapps(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	file := filepath.Join(layer.Path, "temp")
+	if err := os.MkdirAll(file, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create directory %s\n%w", file, err)
 	}
 
-	return nil
-}
+	file = filepath.Join(layer.Path, "webapps")
+	if b.WarFilesExist {
+		if s, _ := b.ConfigurationResolver.Resolve("BP_TOMCAT_STATIC_CONTEXTS"); b.Contexts.RootRedirect || b.Contexts.RootPage != "" || s != "" {
+			b.Logger.Body("WARNING: ROOT and static contexts are only contributed when the application is mounted at a single context path")
+		}
 
-func (b Base) ContributeLogging(layer libcnb.Layer) error {
-	b.Logger.Header(color.BlueString("%s %s", b.LoggingDependency.Name, b.LoggingDependency.Version))
+		if err := os.Symlink(b.ApplicationPath, file); err != nil {
+			return nil, fmt.Errorf("unable to create symlink from %s to %s\n%w", b.ApplicationPath, file, err)
+		}
 
-	artifact, err := b.DependencyCache.Artifact(b.LoggingDependency)
-	if err != nil {
-		return fmt.Errorf("unable to get dependency %s\n%w", b.LoggingDependency.ID, err)
+		return nil, nil
 	}
-	defer artifact.Close()
-
-	b.Logger.Bodyf("Copying to %s/bin", layer.Path)
 
-	file := filepath.Join(layer.Path, "bin", filepath.Base(b.LoggingDependency.URI))
-	if err := sherpa.CopyFile(artifact, file); err != nil {
-		return fmt.Errorf("unable to copy %s to %s\n%w", filepath.Base(b.LoggingDependency.URI), file, err)
+	if err := os.MkdirAll(file, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create directory %s\n%w", file, err)
 	}
 
-	b.Logger.Bodyf("Writing %s/bin/setenv.sh", layer.Path)
-
-	var s string
-	additionalJars, ok := os.LookupEnv("BPI_TOMCAT_ADDITIONAL_JARS")
-	if ok {
-		b.Logger.Bodyf("found BPI_TOMCAT_ADDITIONAL_JARS %q", additionalJars)
-		s = fmt.Sprintf(`CLASSPATH="%s:%s"`, file, additionalJars)
-	} else {
-		s = fmt.Sprintf(`CLASSPATH="%s"`, file)
+	file = filepath.Join(layer.Path, "webapps", b.ContextPath)
+	b.Logger.Headerf("Mounting application at %s", b.ContextPath)
+	if err := os.Symlink(b.ApplicationPath, file); err != nil {
+		return nil, fmt.Errorf("unable to create symlink from %s to %s\n%w", b.ApplicationPath, file, err)
 	}
 
-	file = filepath.Join(layer.Path, "bin", "setenv.sh")
-	if err = os.WriteFile(file, []byte(s), 0755); err != nil {
-		return fmt.Errorf("unable to write file %s\n%w", file, err)
+	c := b.Contexts
+	c.Logger = b.Logger
+	if s, ok := b.ConfigurationResolver.Resolve("BP_TOMCAT_STATIC_CONTEXTS"); ok {
+		var err error
+		if c.StaticContexts, err = ParseStaticContexts(s); err != nil {
+			return nil, fmt.Errorf("unable to parse BP_TOMCAT_STATIC_CONTEXTS\n%w", err)
+		}
+	}
+	if err := c.Contribute(filepath.Join(layer.Path, "webapps")); err != nil {
+		return nil, fmt.Errorf("unable to contribute contexts\n%w", err)
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (b Base) ContributeCatalinaProps(layer libcnb.Layer) error {
-	b.Logger.Header(color.BlueString("Tomcat catalina.properties with altered common.loader"))
-
-	homeProps := filepath.Join(layer.Path, "..", "tomcat", "conf", "catalina.properties")
-	baseProps := filepath.Join(layer.Path, "conf", "catalina.properties")
-
-	if _, err := os.Stat(baseProps); errors.Is(err, os.ErrNotExist) {
-		in, err := os.Open(homeProps)
-		if err != nil {
-			b.Logger.Bodyf("Skipping copying of catalina.properties, unable to open %s", homeProps)
-			return nil
-		}
-		defer in.Close()
-
-		b.Logger.Bodyf("Copying catalina.properties to %s/conf", layer.Path)
-		if err := sherpa.CopyFile(in, baseProps); err != nil {
-			return fmt.Errorf("unable to copy %s to %s\n%w", in.Name(), baseProps, err)
-		}
+func (b Base) ContributeHardening(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	profile, err := ParseHardening(b.Hardening.Profile)
+	if err != nil {
+		return nil, err
 	}
-
-	b.Logger.Body("Altering catalina.properties common.loader")
-	if err := util.ReplaceInCatalinaProps(baseProps); err != nil {
-		return fmt.Errorf("unable to replace in file %s\n%w", baseProps, err)
+	if profile == HardeningNone {
+		return nil, nil
 	}
 
-	return nil
+	b.Logger.Header(color.BlueString("Hardening profile %s", profile))
+	h := b.Hardening
+	h.Logger = b.Logger
+	h.Profile = profile
+	return nil, h.Configure(layer.Path)
 }
 
-func (b Base) writeDependencySBOM(layer libcnb.Layer, syftArtifacts []sbom.SyftArtifact) error {
+func (b Base) ContributeStaticAssetConfiguration(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	rules, err := ParseStaticCacheRules(os.Environ())
+	if err != nil {
+		return nil, err
+	}
 
-	sbomPath := layer.SBOMPath(libcnb.SyftJSON)
-	dep := sbom.NewSyftDependency(layer.Path, syftArtifacts)
-	b.Logger.Debugf("Writing Syft SBOM at %s: %+v", sbomPath, dep)
-	if err := dep.WriteTo(sbomPath); err != nil {
-		return fmt.Errorf("unable to write SBOM\n%w", err)
+	c := b.StaticAssetConfiguration
+	c.CacheRules = rules
+	c.Logger = b.Logger
+	if !c.Precompressed && len(c.CacheRules) == 0 {
+		return nil, nil
 	}
-	return nil
+
+	b.Logger.Header(color.BlueString("Static asset configuration"))
+	return nil, c.Configure(filepath.Join(layer.Path, "conf", "web.xml"))
 }
 
-func (b Base) explodeWarFiles() error {
-	warFiles, err := filepath.Glob(filepath.Join(b.ApplicationPath, "*.war"))
-	if err != nil {
-		return err
+func (b Base) ValidateConfiguration(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	if b.ConfigurationResolver.ResolveBool("BP_TOMCAT_CONFIGURATION_VALIDATION_DISABLED") {
+		return nil, nil
 	}
 
-	for _, warFilePath := range warFiles {
-		b.Logger.Debugf("Extracting: %s\n", warFilePath)
+	b.Logger.Header(color.BlueString("Validating Tomcat configuration"))
 
-		if _, err := os.Stat(warFilePath); err == nil {
-			in, err := os.Open(warFilePath)
-			if err != nil {
-				return fmt.Errorf("An error occurred while extracting %s: %s\n", warFilePath, err)
-			}
-			defer in.Close()
+	var classPath []string
+	for _, name := range []string{"BPI_TOMCAT_ADDITIONAL_JARS", "BPI_TOMCAT_ADDITIONAL_COMMON_JARS"} {
+		if s, ok := os.LookupEnv(name); ok {
+			classPath = append(classPath, strings.FieldsFunc(s, func(r rune) bool { return r == ':' || r == ',' })...)
+		}
+	}
 
-			targetDir := strings.TrimSuffix(warFilePath, filepath.Ext(warFilePath))
-			if err := os.MkdirAll(targetDir, 0755); err != nil {
-				return fmt.Errorf("An error occurred while extracting %s: %s\n", warFilePath, err)
-			}
+	v := ConfigurationValidator{
+		AdditionalClassPath: classPath,
+		CatalinaBase:        layer.Path,
+		CatalinaHome:        filepath.Join(layer.Path, "..", "tomcat"),
+		Logger:              b.Logger,
+	}
+	if err := v.Validate(); err != nil {
+		return nil, fmt.Errorf("unable to validate configuration\n%w", err)
+	}
 
-			if err := crush.Extract(in, targetDir, 0); err != nil {
-				return fmt.Errorf("An error occurred while extracting %s: %s\n", warFilePath, err)
-			}
+	return nil, nil
+}
 
-			err = os.Remove(warFilePath)
-			if err != nil {
-				return fmt.Errorf("An error occurred while removing the .war file: %s\n", err)
-			}
-		}
-	}
-	return nil
+func (b Base) writeDependencySBOM(layer libcnb.Layer, dependencies []libpak.BuildpackDependency) error {
+	d := DependencySBOM{Dependencies: dependencies, Logger: b.Logger}
+	return d.WriteTo(layer, libcnb.SyftJSON, libcnb.CycloneDXJSON, libcnb.SPDXJSON)
 }
 
 func (Base) Name() string {
@@ -0,0 +1,273 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/crush"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
+)
+
+// BaseContributor contributes one part of $CATALINA_BASE.  Base runs its contributors in order, each logging its own
+// section, and writes the dependencies they return to the layer's SBOM.
+type BaseContributor interface {
+	// Contribute modifies the catalina-base layer and returns the dependencies it installed.
+	Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error)
+
+	// Name identifies the contributor in errors.
+	Name() string
+}
+
+// BaseContributorFunc adapts a function to a BaseContributor.
+type BaseContributorFunc struct {
+	ContributorName string
+	Func            func(layer libcnb.Layer) ([]libpak.BuildpackDependency, error)
+}
+
+func (b BaseContributorFunc) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	return b.Func(layer)
+}
+
+func (b BaseContributorFunc) Name() string {
+	return b.ContributorName
+}
+
+// Configuration copies the buildpack's context.xml, logging.properties, server.xml and web.xml to $CATALINA_BASE/conf.
+type Configuration struct {
+	BuildpackPath string
+	Logger        bard.Logger
+}
+
+func (c Configuration) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	file := filepath.Join(layer.Path, "conf")
+	if err := os.MkdirAll(file, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create directory %s\n%w", file, err)
+	}
+
+	for _, name := range []string{"context.xml", "logging.properties", "server.xml", "web.xml"} {
+		c.Logger.Bodyf("Copying %s to %s/conf", name, layer.Path)
+		if err := c.copy(name, filepath.Join(layer.Path, "conf", name)); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (c Configuration) copy(name string, destination string) error {
+	file := filepath.Join(c.BuildpackPath, "resources", name)
+	in, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	if err := sherpa.CopyFile(in, destination); err != nil {
+		return fmt.Errorf("unable to copy %s to %s\n%w", in.Name(), destination, err)
+	}
+
+	return nil
+}
+
+func (Configuration) Name() string {
+	return "configuration"
+}
+
+// SupportLibrary copies a dependency to a directory, lib or bin, of $CATALINA_BASE.
+type SupportLibrary struct {
+	Dependency      libpak.BuildpackDependency
+	DependencyCache libpak.DependencyCache
+	Directory       string
+	Logger          bard.Logger
+}
+
+func (s SupportLibrary) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	if _, err := s.copy(layer); err != nil {
+		return nil, err
+	}
+
+	return []libpak.BuildpackDependency{s.Dependency}, nil
+}
+
+func (s SupportLibrary) copy(layer libcnb.Layer) (string, error) {
+	s.Logger.Header(color.BlueString("%s %s", s.Dependency.Name, s.Dependency.Version))
+
+	artifact, err := s.DependencyCache.Artifact(s.Dependency)
+	if err != nil {
+		return "", fmt.Errorf("unable to get dependency %s\n%w", s.Dependency.ID, err)
+	}
+	defer artifact.Close()
+
+	s.Logger.Bodyf("Copying to %s/%s", layer.Path, s.Directory)
+
+	file := filepath.Join(layer.Path, s.Directory, fileName(s.Dependency.URI))
+	if err := sherpa.CopyFile(artifact, file); err != nil {
+		return "", fmt.Errorf("unable to copy %s to %s\n%w", fileName(s.Dependency.URI), file, err)
+	}
+
+	return file, nil
+}
+
+func (s SupportLibrary) Name() string {
+	return s.Dependency.ID
+}
+
+// fileName returns the last element of the path of a URI, ignoring any query.
+func fileName(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Path != "" {
+		return path.Base(u.Path)
+	}
+	return path.Base(uri)
+}
+
+// LoggingSupport copies the logging support dependency to $CATALINA_BASE/bin and puts it, along with
+// $BPI_TOMCAT_ADDITIONAL_JARS, on the CLASSPATH in bin/setenv.sh.
+type LoggingSupport struct {
+	SupportLibrary
+}
+
+func (l LoggingSupport) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	l.Directory = "bin"
+
+	file, err := l.copy(layer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSetenv(layer, l.Logger, file); err != nil {
+		return nil, err
+	}
+
+	return []libpak.BuildpackDependency{l.Dependency}, nil
+}
+
+// writeSetenv puts the JARs, along with $BPI_TOMCAT_ADDITIONAL_JARS, on the CLASSPATH in bin/setenv.sh.  It does not
+// write the file when there are no JARs.
+func writeSetenv(layer libcnb.Layer, logger bard.Logger, jars ...string) error {
+	additionalJars, ok := os.LookupEnv("BPI_TOMCAT_ADDITIONAL_JARS")
+	if ok {
+		jars = append(jars, additionalJars)
+	}
+	if len(jars) == 0 {
+		return nil
+	}
+
+	logger.Bodyf("Writing %s/bin/setenv.sh", layer.Path)
+	if ok {
+		logger.Bodyf("found BPI_TOMCAT_ADDITIONAL_JARS %q", additionalJars)
+	}
+
+	file := filepath.Join(layer.Path, "bin", "setenv.sh")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(`CLASSPATH="%s"`, strings.Join(jars, ":"))), 0755); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return nil
+}
+
+// ExternalConfiguration expands the external configuration package over $CATALINA_BASE, stripping Strip directory
+// levels.
+type ExternalConfiguration struct {
+	Dependency      libpak.BuildpackDependency
+	DependencyCache libpak.DependencyCache
+	Logger          bard.Logger
+	Strip           string
+}
+
+func (e ExternalConfiguration) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	e.Logger.Header(color.BlueString("%s %s", e.Dependency.Name, e.Dependency.Version))
+
+	artifact, err := e.DependencyCache.Artifact(e.Dependency)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get dependency %s\n%w", e.Dependency.ID, err)
+	}
+	defer artifact.Close()
+
+	e.Logger.Bodyf("Expanding to %s", layer.Path)
+
+	c := 0
+	if e.Strip != "" {
+		if c, err = strconv.Atoi(e.Strip); err != nil {
+			return nil, fmt.Errorf("unable to parse %s to integer\n%w", e.Strip, err)
+		}
+	}
+
+	if err := crush.ExtractTarGz(artifact, layer.Path, c); err != nil {
+		return nil, fmt.Errorf("unable to expand external configuration\n%w", err)
+	}
+
+	return []libpak.BuildpackDependency{e.Dependency}, nil
+}
+
+func (ExternalConfiguration) Name() string {
+	return "external configuration"
+}
+
+// CatalinaProperties copies catalina.properties from $CATALINA_HOME, unless the external configuration provides one,
+// and adds $BPI_TOMCAT_ADDITIONAL_COMMON_JARS to its common.loader.
+type CatalinaProperties struct {
+	Logger bard.Logger
+}
+
+func (c CatalinaProperties) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	c.Logger.Header(color.BlueString("Tomcat catalina.properties with altered common.loader"))
+
+	homeProps := filepath.Join(layer.Path, "..", "tomcat", "conf", "catalina.properties")
+	baseProps := filepath.Join(layer.Path, "conf", "catalina.properties")
+
+	if _, err := os.Stat(baseProps); errors.Is(err, os.ErrNotExist) {
+		in, err := os.Open(homeProps)
+		if err != nil {
+			c.Logger.Bodyf("Skipping copying of catalina.properties, unable to open %s", homeProps)
+			return nil, nil
+		}
+		defer in.Close()
+
+		c.Logger.Bodyf("Copying catalina.properties to %s/conf", layer.Path)
+		if err := sherpa.CopyFile(in, baseProps); err != nil {
+			return nil, fmt.Errorf("unable to copy %s to %s\n%w", in.Name(), baseProps, err)
+		}
+	}
+
+	c.Logger.Body("Altering catalina.properties common.loader")
+	if err := util.ReplaceInCatalinaProps(baseProps); err != nil {
+		return nil, fmt.Errorf("unable to replace in file %s\n%w", baseProps, err)
+	}
+
+	return nil, nil
+}
+
+func (CatalinaProperties) Name() string {
+	return "catalina.properties"
+}
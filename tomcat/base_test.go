@@ -21,7 +21,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 
 	"github.com/buildpacks/libcnb"
@@ -37,6 +36,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 		Expect = NewWithT(t).Expect
 
 		ctx libcnb.BuildContext
+
+		accessLoggingDep libpak.BuildpackDependency
+		lifecycleDep     libpak.BuildpackDependency
+		loggingDep       libpak.BuildpackDependency
 	)
 
 	it.Before(func() {
@@ -57,45 +60,45 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 			To(Succeed())
 
 		Expect(os.MkdirAll(filepath.Join(ctx.Buildpack.Path, "resources"), 0755)).To(Succeed())
-		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "resources", "context.xml"), []byte{}, 0644)).
+		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "resources", "context.xml"), []byte("<Context/>"), 0644)).
 			To(Succeed())
 		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "resources", "logging.properties"), []byte{}, 0644)).
 			To(Succeed())
-		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "resources", "server.xml"), []byte{}, 0644)).
+		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "resources", "server.xml"), []byte("<Server port='-1'/>"), 0644)).
 			To(Succeed())
-		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "resources", "web.xml"), []byte{}, 0644)).
+		Expect(os.WriteFile(filepath.Join(ctx.Buildpack.Path, "resources", "web.xml"), []byte("<web-app/>"), 0644)).
 			To(Succeed())
-	})
 
-	it.After(func() {
-		Expect(os.RemoveAll(ctx.Application.Path)).To(Succeed())
-		Expect(os.RemoveAll(ctx.Buildpack.Path)).To(Succeed())
-		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
-	})
-
-	it("contributes catalina base", func() {
-		accessLoggingDep := libpak.BuildpackDependency{
+		accessLoggingDep = libpak.BuildpackDependency{
 			ID:     "tomcat-access-logging-support",
 			URI:    "https://localhost/stub-tomcat-access-logging-support.jar",
 			SHA256: "d723bfe2ba67dfa92b24e3b6c7b2d0e6a963de7313350e306d470e44e330a5d2",
 			PURL:   "pkg:generic/tomcat-access-logging-support@3.3.0",
 			CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:3.3.0:*:*:*:*:*:*:*"},
 		}
-		lifecycleDep := libpak.BuildpackDependency{
+		lifecycleDep = libpak.BuildpackDependency{
 			ID:     "tomcat-lifecycle-support",
 			URI:    "https://localhost/stub-tomcat-lifecycle-support.jar",
 			SHA256: "723126712c0b22a7fe409664adf1fbb78cf3040e313a82c06696f5058e190534",
 			PURL:   "pkg:generic/tomcat-lifecycle-support@3.3.0",
 			CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-lifecycle-support:3.3.0:*:*:*:*:*:*:*"},
 		}
-		loggingDep := libpak.BuildpackDependency{
+		loggingDep = libpak.BuildpackDependency{
 			ID:     "tomcat-logging-support",
 			URI:    "https://localhost/stub-tomcat-logging-support.jar",
 			SHA256: "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c",
 			PURL:   "pkg:generic/tomcat-logging-support@3.3.0",
 			CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.3.0:*:*:*:*:*:*:*"},
 		}
+	})
 
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Application.Path)).To(Succeed())
+		Expect(os.RemoveAll(ctx.Buildpack.Path)).To(Succeed())
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+	})
+
+	it("contributes catalina base", func() {
 		dc := libpak.DependencyCache{CachePath: "testdata"}
 
 		contributor, entries := tomcat.NewBase(
@@ -103,10 +106,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 			ctx.Buildpack.Path,
 			libpak.ConfigurationResolver{},
 			"test-context-path",
-			accessLoggingDep,
+			&accessLoggingDep,
 			nil,
-			lifecycleDep,
-			loggingDep,
+			&lifecycleDep,
+			&loggingDep,
 			dc,
 			false,
 		)
@@ -151,6 +154,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 
 		Expect(layer.LaunchEnvironment["CATALINA_BASE.default"]).To(Equal(layer.Path))
 		Expect(layer.LaunchEnvironment["CATALINA_OPTS.default"]).To(Equal("-DBPI_TOMCAT_ADDITIONAL_COMMON_JARS=${BPI_TOMCAT_ADDITIONAL_COMMON_JARS} -Dorg.apache.tomcat.util.digester.PROPERTY_SOURCE=org.apache.tomcat.util.digester.EnvironmentPropertySource"))
+
+		Expect(layer.SBOMPath(libcnb.SyftJSON)).To(BeARegularFile())
+		Expect(layer.SBOMPath(libcnb.CycloneDXJSON)).To(BeARegularFile())
+		Expect(layer.SBOMPath(libcnb.SPDXJSON)).To(BeARegularFile())
 	})
 
 	it("contributes custom configuration", func() {
@@ -161,27 +168,6 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 			PURL:   "pkg:generic/tomcat@1.1.1",
 			CPEs:   []string{"cpe:2.3:a:apache:tomcat:1.1.1:*:*:*:*:*:*:*"},
 		}
-		accessLoggingDep := libpak.BuildpackDependency{
-			ID:     "tomcat-access-logging-support",
-			URI:    "https://localhost/stub-tomcat-access-logging-support.jar",
-			SHA256: "d723bfe2ba67dfa92b24e3b6c7b2d0e6a963de7313350e306d470e44e330a5d2",
-			PURL:   "pkg:generic/tomcat-access-logging-support@3.3.0",
-			CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:3.3.0:*:*:*:*:*:*:*"},
-		}
-		lifecycleDep := libpak.BuildpackDependency{
-			ID:     "tomcat-lifecycle-support",
-			URI:    "https://localhost/stub-tomcat-lifecycle-support.jar",
-			SHA256: "723126712c0b22a7fe409664adf1fbb78cf3040e313a82c06696f5058e190534",
-			PURL:   "pkg:generic/tomcat-lifecycle-support@3.3.0",
-			CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-lifecycle-support:3.3.0:*:*:*:*:*:*:*"},
-		}
-		loggingDep := libpak.BuildpackDependency{
-			ID:     "tomcat-logging-support",
-			URI:    "https://localhost/stub-tomcat-logging-support.jar",
-			SHA256: "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c",
-			PURL:   "pkg:generic/tomcat-logging-support@3.3.0",
-			CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.3.0:*:*:*:*:*:*:*"},
-		}
 
 		dc := libpak.DependencyCache{CachePath: "testdata"}
 
@@ -190,10 +176,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 			ctx.Buildpack.Path,
 			libpak.ConfigurationResolver{},
 			"test-context-path",
-			accessLoggingDep,
+			&accessLoggingDep,
 			&externalConfigurationDep,
-			lifecycleDep,
-			loggingDep,
+			&lifecycleDep,
+			&loggingDep,
 			dc,
 			false,
 		)
@@ -220,6 +206,86 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 		Expect(filepath.Join(layer.Path, "fixture-marker")).To(BeARegularFile())
 	})
 
+	it("records every input in the layer metadata", func() {
+		t.Setenv("BPI_TOMCAT_ADDITIONAL_JARS", "/layers/test-buildpack/foo/bar.jar")
+		t.Setenv("BP_TOMCAT_CONFIGURATION_VALIDATION_DISABLED", "true")
+		t.Setenv("BP_TOMCAT_ENV_PROPERTY_SOURCE_DISABLED", "true")
+		t.Setenv("BP_TOMCAT_EXT_CONF_STRIP", "1")
+
+		contributor, _ := tomcat.NewBase(
+			ctx.Application.Path,
+			ctx.Buildpack.Path,
+			libpak.ConfigurationResolver{},
+			"test-context-path",
+			&accessLoggingDep,
+			nil,
+			&lifecycleDep,
+			&loggingDep,
+			libpak.DependencyCache{CachePath: "testdata"},
+			true,
+		)
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = contributor.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Metadata).To(HaveKeyWithValue("additional-jars", "/layers/test-buildpack/foo/bar.jar"))
+		Expect(layer.Metadata).To(HaveKeyWithValue("application-path", ctx.Application.Path))
+		Expect(layer.Metadata).To(HaveKeyWithValue("configuration-validation-disabled", true))
+		Expect(layer.Metadata).To(HaveKeyWithValue("context-path", "test-context-path"))
+		Expect(layer.Metadata).To(HaveKeyWithValue("environment-property-source-disabled", true))
+		Expect(layer.Metadata).To(HaveKeyWithValue("external-configuration-strip", "1"))
+		Expect(layer.Metadata).To(HaveKeyWithValue("war-files-exist", true))
+		Expect(layer.Metadata).To(HaveKey("resources"))
+	})
+
+	it("runs additional contributors", func() {
+		contributor, _ := tomcat.NewBase(
+			ctx.Application.Path,
+			ctx.Buildpack.Path,
+			libpak.ConfigurationResolver{},
+			"test-context-path",
+			&accessLoggingDep,
+			nil,
+			&lifecycleDep,
+			&loggingDep,
+			libpak.DependencyCache{CachePath: "testdata"},
+			false,
+		)
+
+		contributor.AdditionalContributors = append(contributor.AdditionalContributors, tomcat.BaseContributorFunc{
+			ContributorName: "test-contributor",
+			Func: func(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+				Expect(filepath.Join(layer.Path, "conf", "server.xml")).To(BeARegularFile())
+				Expect(filepath.Join(layer.Path, "webapps")).NotTo(BeADirectory())
+				return []libpak.BuildpackDependency{{ID: "test-dependency", Name: "Test Dependency", Version: "1.1.1"}}, nil
+			},
+		})
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = contributor.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.ReadFile(layer.SBOMPath(libcnb.SyftJSON))).To(ContainSubstring("Test Dependency"))
+
+		contributor.AdditionalContributors = []tomcat.BaseContributor{tomcat.BaseContributorFunc{
+			ContributorName: "test-contributor",
+			Func: func(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+				return nil, fmt.Errorf("test-error")
+			},
+		}}
+
+		layer, err = ctx.Layers.Layer("test-failing-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = contributor.Contribute(layer)
+		Expect(err).To(MatchError("unable to contribute test-contributor\ntest-error"))
+	})
+
 	context("$BP_TOMCAT_EXT_CONF_STRIP", func() {
 		it.Before(func() {
 			Expect(os.Setenv("BP_TOMCAT_EXT_CONF_STRIP", "1")).To(Succeed())
@@ -237,27 +303,6 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 				PURL:   "pkg:generic/tomcat@1.1.1",
 				CPEs:   []string{"cpe:2.3:a:apache:tomcat:1.1.1:*:*:*:*:*:*:*"},
 			}
-			accessLoggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-access-logging-support",
-				URI:    "https://localhost/stub-tomcat-access-logging-support.jar",
-				SHA256: "d723bfe2ba67dfa92b24e3b6c7b2d0e6a963de7313350e306d470e44e330a5d2",
-				PURL:   "pkg:generic/tomcat-access-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			lifecycleDep := libpak.BuildpackDependency{
-				ID:     "tomcat-lifecycle-support",
-				URI:    "https://localhost/stub-tomcat-lifecycle-support.jar",
-				SHA256: "723126712c0b22a7fe409664adf1fbb78cf3040e313a82c06696f5058e190534",
-				PURL:   "pkg:generic/tomcat-lifecycle-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-lifecycle-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			loggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-logging-support",
-				URI:    "https://localhost/stub-tomcat-logging-support.jar",
-				SHA256: "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c",
-				PURL:   "pkg:generic/tomcat-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
 
 			dc := libpak.DependencyCache{CachePath: "testdata"}
 
@@ -266,10 +311,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 				ctx.Buildpack.Path,
 				libpak.ConfigurationResolver{},
 				"test-context-path",
-				accessLoggingDep,
+				&accessLoggingDep,
 				&externalConfigurationDep,
-				lifecycleDep,
-				loggingDep,
+				&lifecycleDep,
+				&loggingDep,
 				dc,
 				false,
 			)
@@ -297,6 +342,46 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("$BP_TOMCAT_ROOT_REDIRECT and $BP_TOMCAT_STATIC_CONTEXTS", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_TOMCAT_ROOT_REDIRECT", "true")).To(Succeed())
+			Expect(os.Setenv("BP_TOMCAT_STATIC_CONTEXTS", "public=/assets")).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "public"), 0755)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_TOMCAT_ROOT_REDIRECT")).To(Succeed())
+			Expect(os.Unsetenv("BP_TOMCAT_STATIC_CONTEXTS")).To(Succeed())
+		})
+
+		it("contributes ROOT and static contexts", func() {
+			dc := libpak.DependencyCache{CachePath: "testdata"}
+
+			contributor, _ := tomcat.NewBase(
+				ctx.Application.Path,
+				ctx.Buildpack.Path,
+				libpak.ConfigurationResolver{},
+				"test-context-path",
+				&accessLoggingDep,
+				nil,
+				&lifecycleDep,
+				&loggingDep,
+				dc,
+				false,
+			)
+
+			layer, err := ctx.Layers.Layer("test-layer")
+			Expect(err).NotTo(HaveOccurred())
+
+			layer, err = contributor.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.ReadFile(filepath.Join(layer.Path, "webapps", "ROOT", "WEB-INF", "rewrite.config"))).
+				To(ContainSubstring("/test-context-path/"))
+			Expect(os.Readlink(filepath.Join(layer.Path, "webapps", "assets"))).To(Equal(filepath.Join(ctx.Application.Path, "public")))
+		})
+	})
+
 	context("$BP_TOMCAT_ENV_PROPERTY_SOURCE_DISABLED", func() {
 		it.Before(func() {
 			Expect(os.Setenv("BP_TOMCAT_ENV_PROPERTY_SOURCE_DISABLED", "true")).To(Succeed())
@@ -307,28 +392,6 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 		})
 
 		it("environment property source can be disabled", func() {
-			accessLoggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-access-logging-support",
-				URI:    "https://localhost/stub-tomcat-access-logging-support.jar",
-				SHA256: "d723bfe2ba67dfa92b24e3b6c7b2d0e6a963de7313350e306d470e44e330a5d2",
-				PURL:   "pkg:generic/tomcat-access-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			lifecycleDep := libpak.BuildpackDependency{
-				ID:     "tomcat-lifecycle-support",
-				URI:    "https://localhost/stub-tomcat-lifecycle-support.jar",
-				SHA256: "723126712c0b22a7fe409664adf1fbb78cf3040e313a82c06696f5058e190534",
-				PURL:   "pkg:generic/tomcat-lifecycle-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-lifecycle-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			loggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-logging-support",
-				URI:    "https://localhost/stub-tomcat-logging-support.jar",
-				SHA256: "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c",
-				PURL:   "pkg:generic/tomcat-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-
 			dc := libpak.DependencyCache{CachePath: "testdata"}
 
 			contributor, entries := tomcat.NewBase(
@@ -336,10 +399,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 				ctx.Buildpack.Path,
 				libpak.ConfigurationResolver{},
 				"test-context-path",
-				accessLoggingDep,
+				&accessLoggingDep,
 				nil,
-				lifecycleDep,
-				loggingDep,
+				&lifecycleDep,
+				&loggingDep,
 				dc,
 				false,
 			)
@@ -388,34 +451,48 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 
 	})
 
+	context("$BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED")).To(Succeed())
+		})
+
+		it("configures service binding property source", func() {
+			dc := libpak.DependencyCache{CachePath: "testdata"}
+
+			contributor, _ := tomcat.NewBase(
+				ctx.Application.Path,
+				ctx.Buildpack.Path,
+				libpak.ConfigurationResolver{},
+				"test-context-path",
+				&accessLoggingDep,
+				nil,
+				&lifecycleDep,
+				&loggingDep,
+				dc,
+				false,
+			)
+
+			layer, err := ctx.Layers.Layer("test-layer")
+			Expect(err).NotTo(HaveOccurred())
+
+			layer, err = contributor.Contribute(layer)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layer.LaunchEnvironment["CATALINA_OPTS.default"]).To(Equal("-DBPI_TOMCAT_ADDITIONAL_COMMON_JARS=${BPI_TOMCAT_ADDITIONAL_COMMON_JARS} " +
+				"-Dorg.apache.tomcat.util.digester.PROPERTY_SOURCE=org.apache.tomcat.util.digester.ServiceBindingPropertySource,org.apache.tomcat.util.digester.EnvironmentPropertySource"))
+		})
+	})
+
 	context("$BPI_TOMCAT_ADDITIONAL_JARS is set", func() {
 		it.Before(func() {
 			t.Setenv("BPI_TOMCAT_ADDITIONAL_JARS", "/layers/test-buildpack/foo/bar.jar")
 		})
 
 		it("additional jar is added to classpath", func() {
-			accessLoggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-access-logging-support",
-				URI:    "https://localhost/stub-tomcat-access-logging-support.jar",
-				SHA256: "d723bfe2ba67dfa92b24e3b6c7b2d0e6a963de7313350e306d470e44e330a5d2",
-				PURL:   "pkg:generic/tomcat-access-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			lifecycleDep := libpak.BuildpackDependency{
-				ID:     "tomcat-lifecycle-support",
-				URI:    "https://localhost/stub-tomcat-lifecycle-support.jar",
-				SHA256: "723126712c0b22a7fe409664adf1fbb78cf3040e313a82c06696f5058e190534",
-				PURL:   "pkg:generic/tomcat-lifecycle-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-lifecycle-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			loggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-logging-support",
-				URI:    "https://localhost/stub-tomcat-logging-support.jar",
-				SHA256: "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c",
-				PURL:   "pkg:generic/tomcat-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-
 			dc := libpak.DependencyCache{CachePath: "testdata"}
 
 			contributor, entries := tomcat.NewBase(
@@ -423,10 +500,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 				ctx.Buildpack.Path,
 				libpak.ConfigurationResolver{},
 				"test-context-path",
-				accessLoggingDep,
+				&accessLoggingDep,
 				nil,
-				lifecycleDep,
-				loggingDep,
+				&lifecycleDep,
+				&loggingDep,
 				dc,
 				false,
 			)
@@ -477,29 +554,7 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 			}
 		})
 
-		it("Multiple war files have been exploded in application path", func() {
-			accessLoggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-access-logging-support",
-				URI:    "https://localhost/stub-tomcat-access-logging-support.jar",
-				SHA256: "d723bfe2ba67dfa92b24e3b6c7b2d0e6a963de7313350e306d470e44e330a5d2",
-				PURL:   "pkg:generic/tomcat-access-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			lifecycleDep := libpak.BuildpackDependency{
-				ID:     "tomcat-lifecycle-support",
-				URI:    "https://localhost/stub-tomcat-lifecycle-support.jar",
-				SHA256: "723126712c0b22a7fe409664adf1fbb78cf3040e313a82c06696f5058e190534",
-				PURL:   "pkg:generic/tomcat-lifecycle-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-lifecycle-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-			loggingDep := libpak.BuildpackDependency{
-				ID:     "tomcat-logging-support",
-				URI:    "https://localhost/stub-tomcat-logging-support.jar",
-				SHA256: "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c",
-				PURL:   "pkg:generic/tomcat-logging-support@3.3.0",
-				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.3.0:*:*:*:*:*:*:*"},
-			}
-
+		it("webapps is linked to the application path", func() {
 			dc := libpak.DependencyCache{CachePath: "testdata"}
 
 			contributor, entries := tomcat.NewBase(
@@ -507,10 +562,10 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 				ctx.Buildpack.Path,
 				libpak.ConfigurationResolver{},
 				"test-context-path",
-				accessLoggingDep,
+				&accessLoggingDep,
 				nil,
-				lifecycleDep,
-				loggingDep,
+				&lifecycleDep,
+				&loggingDep,
 				dc,
 				true,
 			)
@@ -534,8 +589,7 @@ func testBase(t *testing.T, context spec.G, it spec.S) {
 
 			Expect(os.Readlink(filepath.Join(layer.Path, "webapps"))).To(Equal(ctx.Application.Path))
 			for _, file := range files {
-				targetDir := strings.TrimSuffix(file, filepath.Ext(file))
-				Expect(filepath.Join(layer.Path, "webapps", targetDir, "META-INF", "MANIFEST.MF")).To(BeARegularFile())
+				Expect(filepath.Join(layer.Path, "webapps", file)).To(BeARegularFile())
 			}
 		})
 	})
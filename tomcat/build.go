@@ -31,8 +31,6 @@ import (
 	"github.com/heroku/color"
 
 	"github.com/buildpacks/libcnb"
-	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
-	"github.com/paketo-buildpacks/libjvm"
 	"github.com/paketo-buildpacks/libpak"
 	"github.com/paketo-buildpacks/libpak/bard"
 )
@@ -52,40 +50,72 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		return result, nil
 	}
 
-	warFilesExist, _ := util.ContainsWarFiles(context.Application.Path)
-	if warFilesExist {
-		b.Logger.Infof("%s contains war files.", context.Application.Path)
-	} else {
-		m, err := libjvm.NewManifest(context.Application.Path)
-		if err != nil {
-			return libcnb.BuildResult{}, fmt.Errorf("unable to read manifest\n%w", err)
+	layout, err := NewApplicationLayout(context.Application.Path)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to inspect application\n%w", err)
+	}
+
+	deployExecutableWar := sherpa.ResolveBool("BP_TOMCAT_DEPLOY_EXECUTABLE_WAR")
+	if !layout.Kind.Deployable() && !(layout.Kind == ApplicationExecutableWar && deployExecutableWar) {
+		for _, entry := range context.Plan.Entries {
+			result.Unmet = append(result.Unmet, libcnb.UnmetPlanEntry{Name: entry.Name})
 		}
+		return result, nil
+	}
 
-		if _, ok := m.Get("Main-Class"); ok {
-			for _, entry := range context.Plan.Entries {
-				result.Unmet = append(result.Unmet, libcnb.UnmetPlanEntry{Name: entry.Name})
-			}
-			return result, nil
+	b.Logger.Title(context.Buildpack)
+	layout.Report(b.Logger)
+
+	if layout, err = layout.ExplodeBuildOutput(b.Logger); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to explode build output\n%w", err)
+	}
+
+	webappsPath, warFilesExist := layout.Mount()
+
+	if layout.Kind == ApplicationWarFiles {
+		w := WarFiles{ApplicationPath: context.Application.Path, Logger: b.Logger}
+		if err := w.Explode(); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to explode war files in %s\n%w", context.Application.Path, err)
 		}
 
-		file := filepath.Join(context.Application.Path, "WEB-INF")
-		if _, err := os.Stat(file); err != nil && !os.IsNotExist(err) {
-			return libcnb.BuildResult{}, fmt.Errorf("unable to stat file %s\n%w", file, err)
-		} else if os.IsNotExist(err) {
-			for _, entry := range context.Plan.Entries {
-				result.Unmet = append(result.Unmet, libcnb.UnmetPlanEntry{Name: entry.Name})
-			}
-			return result, nil
+		if layout, err = NewApplicationLayout(context.Application.Path); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to inspect application\n%w", err)
 		}
 	}
 
-	b.Logger.Title(context.Buildpack)
+	if deployExecutableWar {
+		for _, w := range layout.Webapps {
+			e := ExecutableWar{Logger: b.Logger, Path: w}
+			if err := e.Strip(); err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to strip executable war %s\n%w", w, err)
+			}
+		}
+	}
 
 	cr, err := libpak.NewConfigurationResolver(context.Buildpack, &b.Logger)
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to create configuration resolver\n%w", err)
 	}
 
+	if cr.ResolveBool("BP_TOMCAT_STATIC_PRECOMPRESSION_ENABLED") {
+		dirs := append([]string{}, layout.Webapps...)
+		if s, ok := cr.Resolve("BP_TOMCAT_STATIC_CONTEXTS"); ok {
+			contexts, err := ParseStaticContexts(s)
+			if err != nil {
+				return libcnb.BuildResult{}, fmt.Errorf("unable to parse BP_TOMCAT_STATIC_CONTEXTS\n%w", err)
+			}
+			for _, c := range contexts {
+				dirs = append(dirs, filepath.Join(context.Application.Path, c.Directory))
+			}
+		}
+
+		b.Logger.Header(color.BlueString("Pre-compressing static assets"))
+		sa := StaticAssets{Directories: dirs, Logger: b.Logger}
+		if err := sa.Precompress(); err != nil {
+			return libcnb.BuildResult{}, err
+		}
+	}
+
 	dr, err := libpak.NewDependencyResolver(context)
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to create dependency resolver\n%w", err)
@@ -97,35 +127,164 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	}
 	dc.Logger = b.Logger
 
-	v, _ := cr.Resolve("BP_TOMCAT_VERSION")
+	v, ok := cr.Resolve("BP_TOMCAT_VERSION")
+	if !ok {
+		if s, found, err := SelectTomcatVersion(context.Buildpack, layout, b.Logger); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to select Tomcat version\n%w", err)
+		} else if found {
+			v = s
+		}
+	}
+
 	tomcatDep, err := dr.Resolve("tomcat", v)
 	if err != nil {
 		return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency\n%w", err)
 	}
 
+	if jvm, ok := cr.Resolve("BP_JVM_VERSION"); ok {
+		if err := CheckJavaVersion(tomcatDep, jvm); err != nil {
+			return libcnb.BuildResult{}, err
+		}
+	}
+	// the JRE is only present at build time when it was requested for AppCDS, otherwise the minimum-version in the jre
+	// plan entry constrains it
+	if javaHome, ok := os.LookupEnv("JAVA_HOME"); ok {
+		if err := CheckJavaHome(tomcatDep, javaHome); err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to check the Java version Tomcat %s runs on\n%w", tomcatDep.Version, err)
+		}
+	}
+
+	profile, _ := cr.Resolve("BP_TOMCAT_HARDENING")
+	hardening, err := ParseHardening(profile)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to parse BP_TOMCAT_HARDENING\n%w", err)
+	}
+	sameSiteCookies, _ := cr.Resolve("BP_TOMCAT_HARDENING_SAME_SITE_COOKIES")
+	if _, err := ParseSameSiteCookies(sameSiteCookies); err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to parse BP_TOMCAT_HARDENING_SAME_SITE_COOKIES\n%w", err)
+	}
+
 	home, be := NewHome(tomcatDep, dc)
 	home.Logger = b.Logger
+	if !cr.ResolveBool("BP_TOMCAT_HOME_PRUNING_DISABLED") {
+		home.Prune = HomePrunePatterns
+	} else if hardening == HardeningStrict {
+		home.Prune = DefaultWebappPrunePatterns()
+	}
+
+	managerEnabled := cr.ResolveBool("BP_TOMCAT_MANAGER_ENABLED")
+	if managerEnabled {
+		home.Prune = removePattern(home.Prune, "webapps/manager")
+	}
 	result.Layers = append(result.Layers, home)
 	result.BOM.Entries = append(result.BOM.Entries, be)
 
-	h, be := libpak.NewHelperLayer(context.Buildpack, "access-logging-support")
+	appCDSEnabled := cr.ResolveBool("BP_TOMCAT_APPCDS_ENABLED")
+	if appCDSEnabled && libpak.IsTinyStack(context.StackID) {
+		b.Logger.Infof(color.YellowString("WARNING: BP_TOMCAT_APPCDS_ENABLED is not supported on the Tiny stack as Tomcat is not started with catalina.sh"))
+		appCDSEnabled = false
+	}
+	if appCDSEnabled {
+		javaHome, ok := os.LookupEnv("JAVA_HOME")
+		if !ok {
+			return libcnb.BuildResult{}, fmt.Errorf("BP_TOMCAT_APPCDS_ENABLED requires a JRE at build time, but $JAVA_HOME is not set")
+		}
+
+		version, err := javaRelease(javaHome, "JAVA_VERSION")
+		if err != nil {
+			return libcnb.BuildResult{}, err
+		}
+		major, err := JavaMajorVersion(version)
+		if err != nil {
+			return libcnb.BuildResult{}, err
+		}
+		if major < AppCDSMinimumJava {
+			b.Logger.Infof(color.YellowString("WARNING: BP_TOMCAT_APPCDS_ENABLED requires Java %d or later, but the JRE in %s is %s", AppCDSMinimumJava, javaHome, version))
+			appCDSEnabled = false
+		}
+	}
+
+	var helpers []string
+	if supportEnabled(cr, "BP_TOMCAT_ACCESS_LOGGING_SUPPORT_ENABLED") {
+		helpers = append(helpers, "access-logging-support")
+	}
+	if appCDSEnabled {
+		helpers = append(helpers, "app-cds")
+	}
+	if cr.ResolveBool("BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED") {
+		helpers = append(helpers, "binding-placeholders")
+	}
+	helpers = append(helpers, "connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads")
+	if managerEnabled {
+		helpers = append(helpers, "manager-users")
+	}
+	otelEnabled := cr.ResolveBool("BP_TOMCAT_OTEL_ENABLED")
+	if otelEnabled {
+		helpers = append(helpers, "opentelemetry")
+	}
+
+	h, be := libpak.NewHelperLayer(context.Buildpack, helpers...)
 	h.Logger = b.Logger
 	result.Layers = append(result.Layers, h)
 	result.BOM.Entries = append(result.BOM.Entries, be)
 
-	accessLoggingDependency, err := dr.Resolve("tomcat-access-logging-support", "")
+	var supportDependencies []libpak.BuildpackDependency
+	resolveSupport := func(name string, id string) (*libpak.BuildpackDependency, error) {
+		if !supportEnabled(cr, name) {
+			b.Logger.Bodyf("Skipping %s, %s is false", id, name)
+			return nil, nil
+		}
+
+		d, err := dr.Resolve(id, "")
+		if err != nil {
+			return nil, fmt.Errorf("unable to find dependency\n%w", err)
+		}
+		supportDependencies = append(supportDependencies, d)
+		return &d, nil
+	}
+
+	accessLoggingDependency, err := resolveSupport("BP_TOMCAT_ACCESS_LOGGING_SUPPORT_ENABLED", "tomcat-access-logging-support")
 	if err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency\n%w", err)
+		return libcnb.BuildResult{}, err
 	}
 
-	lifecycleDependency, err := dr.Resolve("tomcat-lifecycle-support", "")
+	lifecycleDependency, err := resolveSupport("BP_TOMCAT_LIFECYCLE_SUPPORT_ENABLED", "tomcat-lifecycle-support")
 	if err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency\n%w", err)
+		return libcnb.BuildResult{}, err
 	}
 
-	loggingDependency, err := dr.Resolve("tomcat-logging-support", "")
+	loggingDependency, err := resolveSupport("BP_TOMCAT_LOGGING_SUPPORT_ENABLED", "tomcat-logging-support")
 	if err != nil {
-		return libcnb.BuildResult{}, fmt.Errorf("unable to find dependency\n%w", err)
+		return libcnb.BuildResult{}, err
+	}
+
+	advisories, err := NewAdvisoryDatabase(cr, context.Platform.Bindings)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to read advisories\n%w", err)
+	}
+	failSeverity, _ := cr.Resolve("BP_TOMCAT_ADVISORY_FAIL_SEVERITY")
+	ac, err := NewAdvisoryChecker(advisories, failSeverity, b.Logger)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to parse BP_TOMCAT_ADVISORY_FAIL_SEVERITY\n%w", err)
+	}
+
+	extraLibraries, err := NewExtraLibraries(context.Buildpack, dr, cr, context.StackID)
+	if err != nil {
+		return libcnb.BuildResult{}, fmt.Errorf("unable to resolve extra libraries\n%w", err)
+	}
+
+	var otelDependency *libpak.BuildpackDependency
+	if otelEnabled {
+		d, err := NewOpenTelemetryAgentDependency(cr, dr, context.StackID)
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to resolve the OpenTelemetry Java agent\n%w", err)
+		}
+		otelDependency = &d
+		supportDependencies = append(supportDependencies, d)
+	}
+
+	if err := ac.Check(append(append([]libpak.BuildpackDependency{tomcatDep}, supportDependencies...), extraLibraries...)...); err != nil {
+		return libcnb.BuildResult{}, err
 	}
 
 	var externalConfigurationDependency *libpak.BuildpackDependency
@@ -150,14 +309,47 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 		}
 	}
 
-	base, bomEntries := NewBase(context.Application.Path, context.Buildpack.Path, cr, b.ContextPath(cr), accessLoggingDependency, externalConfigurationDependency, lifecycleDependency, loggingDependency, dc, warFilesExist)
+	base, bomEntries := NewBase(webappsPath, context.Buildpack.Path, cr, b.ContextPath(cr), accessLoggingDependency, externalConfigurationDependency, lifecycleDependency, loggingDependency, dc, warFilesExist)
 
 	base.Logger = b.Logger
+	base.ExtraLibraries = extraLibraries
+	for _, d := range extraLibraries {
+		entry := d.AsBOMEntry()
+		entry.Metadata["layer"] = base.Name()
+		entry.Launch = true
+		bomEntries = append(bomEntries, entry)
+	}
+	if otelDependency != nil {
+		base.OpenTelemetry = &OpenTelemetry{Dependency: *otelDependency}
+
+		entry := base.OpenTelemetry.Dependency.AsBOMEntry()
+		entry.Metadata["layer"] = base.Name()
+		entry.Launch = true
+		bomEntries = append(bomEntries, entry)
+	}
+	if appCDSEnabled {
+		// the training run needs the contents of catalina-base even when the layer is reused
+		base.LayerContributor.ExpectedTypes.Cache = true
+	}
 	result.Layers = append(result.Layers, base)
 	if bomEntries != nil {
 		result.BOM.Entries = append(result.BOM.Entries, bomEntries...)
 	}
 
+	webappSBOM := NewWebappSBOM(webappsPath, base.ContextPath, warFilesExist)
+	webappSBOM.Logger = b.Logger
+	result.Layers = append(result.Layers, webappSBOM)
+
+	if appCDSEnabled {
+		appCDS, err := NewAppCDS(context.Application.Path, os.Getenv("JAVA_HOME"), base,
+			filepath.Join(context.Layers.Path, "tomcat"), filepath.Join(context.Layers.Path, base.Name()))
+		if err != nil {
+			return libcnb.BuildResult{}, fmt.Errorf("unable to create AppCDS\n%w", err)
+		}
+		appCDS.Logger = b.Logger
+		result.Layers = append(result.Layers, appCDS)
+	}
+
 	command := "sh"
 	arguments := []string{filepath.Join(context.Layers.Path, "tomcat", "bin", "catalina.sh"), "run"}
 
@@ -184,6 +376,37 @@ func (b Build) Build(context libcnb.BuildContext) (libcnb.BuildResult, error) {
 	return result, nil
 }
 
+// SelectTomcatVersion selects the Tomcat line from the deployment descriptors of the application's webapps.
+func SelectTomcatVersion(buildpack libcnb.Buildpack, layout ApplicationLayout, logger bard.Logger) (string, bool, error) {
+	var descriptors []DeploymentDescriptor
+	for _, w := range layout.Webapps {
+		d, err := ReadDeploymentDescriptors(w, logger)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to read deployment descriptors of %s\n%w", w, err)
+		}
+		descriptors = append(descriptors, d...)
+	}
+
+	if len(descriptors) == 0 {
+		return "", false, nil
+	}
+
+	md, err := libpak.NewBuildpackMetadata(buildpack.Metadata)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to unmarshal buildpack metadata\n%w", err)
+	}
+
+	logger.Header(color.BlueString("Inspecting deployment descriptors"))
+	v, found, err := TomcatVersionSelector{Dependencies: md.Dependencies, Logger: logger}.Select(descriptors)
+	if err != nil {
+		return "", false, err
+	} else if found {
+		logger.Bodyf("Selected Tomcat %s", v)
+	}
+
+	return v, found, nil
+}
+
 func (b Build) ContextPath(configurationResolver libpak.ConfigurationResolver) string {
 	cp := "ROOT"
 	if s, ok := configurationResolver.Resolve("BP_TOMCAT_CONTEXT_PATH"); ok {
@@ -196,7 +419,7 @@ func (b Build) ContextPath(configurationResolver libpak.ConfigurationResolver) s
 	return cp
 }
 
-func (b Build) tinyStartCommand(homePath, basePath string, loggingDep libpak.BuildpackDependency) (string, []string) {
+func (b Build) tinyStartCommand(homePath, basePath string, loggingDep *libpak.BuildpackDependency) (string, []string) {
 	command := "java"
 
 	arguments := []string{
@@ -206,11 +429,14 @@ func (b Build) tinyStartCommand(homePath, basePath string, loggingDep libpak.Bui
 
 	arguments = append(arguments, sherpa.GetEnvWithDefault("JSSE_OPTS", "-Djdk.tls.ephemeralDHKeySize=2048"))
 
-	classpath := []string{
-		fmt.Sprintf("%s/bin/%s", basePath, path.Base(loggingDep.URI)),
+	var classpath []string
+	if loggingDep != nil {
+		classpath = append(classpath, fmt.Sprintf("%s/bin/%s", basePath, path.Base(loggingDep.URI)))
+	}
+	classpath = append(classpath,
 		fmt.Sprintf("%s/bin/bootstrap.jar", homePath),
 		fmt.Sprintf("%s/bin/tomcat-juli.jar", homePath),
-	}
+	)
 	arguments = append(arguments, "-classpath", strings.Join(classpath, ":"))
 
 	arguments = append(arguments,
@@ -223,3 +449,22 @@ func (b Build) tinyStartCommand(homePath, basePath string, loggingDep libpak.Bui
 
 	return command, arguments
 }
+
+// supportEnabled returns whether a support component is enabled.  Support components are enabled unless their
+// *_SUPPORT_ENABLED configuration is set to false.
+func supportEnabled(configurationResolver libpak.ConfigurationResolver, name string) bool {
+	if _, ok := configurationResolver.Resolve(name); !ok {
+		return true
+	}
+	return configurationResolver.ResolveBool(name)
+}
+
+func removePattern(patterns []string, pattern string) []string {
+	var result []string
+	for _, p := range patterns {
+		if p != pattern {
+			result = append(result, p)
+		}
+	}
+	return result
+}
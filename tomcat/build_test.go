@@ -81,6 +81,122 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(result.Unmet[1].Name).To(Equal("java-app-server"))
 	})
 
+	context("$BP_TOMCAT_DEPLOY_EXECUTABLE_WAR", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_TOMCAT_DEPLOY_EXECUTABLE_WAR", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_TOMCAT_DEPLOY_EXECUTABLE_WAR")).To(Succeed())
+		})
+
+		it("contributes Tomcat and strips the executable war", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF", "lib-provided"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "META-INF", "MANIFEST.MF"),
+				[]byte(`Main-Class: org.springframework.boot.loader.WarLauncher`), 0644)).To(Succeed())
+
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{
+						"id":      "tomcat",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat@1.1.1",
+						"cpes":    "cpe:2.3:a:apache:tomcat:1.1.1:*:*:*:*:*:*:*",
+					},
+					{
+						"id":      "tomcat-access-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat-access-logging-support@1.1.1",
+						"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:1.1.1:*:*:*:*:*:*:*",
+					},
+					{
+						"id":      "tomcat-lifecycle-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat-lifecycle-logging-support@1.1.1",
+						"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-lifecycle-logging-support:1.1.1:*:*:*:*:*:*:*",
+					},
+					{
+						"id":      "tomcat-logging-support",
+						"version": "1.1.1",
+						"uri":     "https://example.com/releases/tomcat-logging-support-1.1.1.RELEASE.jar",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat-logging-support@1.1.1",
+						"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-logging-support:1.1.1:*:*:*:*:*:*:*",
+					},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+
+			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(4))
+			Expect(result.Unmet).To(BeEmpty())
+			Expect(filepath.Join(ctx.Application.Path, "WEB-INF", "lib-provided")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	context("$BP_TOMCAT_MANAGER_ENABLED", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_TOMCAT_MANAGER_ENABLED", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_TOMCAT_MANAGER_ENABLED")).To(Succeed())
+		})
+
+		it("keeps the manager webapp and contributes the manager-users helper", func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{
+						"id":      "tomcat",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat@1.1.1",
+						"cpes":    "cpe:2.3:a:apache:tomcat:1.1.1:*:*:*:*:*:*:*",
+					},
+					{
+						"id":      "tomcat-access-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat-access-logging-support@1.1.1",
+						"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:1.1.1:*:*:*:*:*:*:*",
+					},
+					{
+						"id":      "tomcat-lifecycle-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat-lifecycle-logging-support@1.1.1",
+						"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-lifecycle-logging-support:1.1.1:*:*:*:*:*:*:*",
+					},
+					{
+						"id":      "tomcat-logging-support",
+						"version": "1.1.1",
+						"uri":     "https://example.com/releases/tomcat-logging-support-1.1.1.RELEASE.jar",
+						"stacks":  []interface{}{"test-stack-id"},
+						"purl":    "pkg:generic/tomcat-logging-support@1.1.1",
+						"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-logging-support:1.1.1:*:*:*:*:*:*:*",
+					},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+
+			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[0].(tomcat.Home).Prune).NotTo(ContainElement("webapps/manager"))
+			Expect(result.Layers[0].(tomcat.Home).Prune).To(ContainElement("webapps/examples"))
+			Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"access-logging-support", "connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads", "manager-users"}))
+			Expect(result.Layers[2].(tomcat.Base).Manager).To(Equal(&tomcat.Manager{ContextName: "manager"}))
+		})
+	})
+
 	it("does not contribute Tomcat if java-app-server missing from buildplan", func() {
 		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
 
@@ -173,11 +289,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			libcnb.Process{Type: "web", Command: "sh", Arguments: []string{"tomcat/bin/catalina.sh", "run"}, Direct: true, Default: true},
 		))
 
-		Expect(result.Layers).To(HaveLen(3))
+		Expect(result.Layers).To(HaveLen(4))
 		Expect(result.Layers[0].Name()).To(Equal("tomcat"))
 		Expect(result.Layers[1].Name()).To(Equal("helper"))
-		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"access-logging-support"}))
+		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"access-logging-support", "connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads"}))
 		Expect(result.Layers[2].Name()).To(Equal("catalina-base"))
+		Expect(result.Layers[3].Name()).To(Equal("webapp-sbom"))
 
 		Expect(result.BOM.Entries).To(HaveLen(5))
 		Expect(result.BOM.Entries[0].Name).To(Equal("tomcat"))
@@ -199,6 +316,139 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 	})
 
+	it("skips disabled support components", func() {
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+		ctx.Buildpack.Metadata = map[string]interface{}{
+			"dependencies": []map[string]interface{}{
+				{
+					"id":      "tomcat",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			},
+		}
+		ctx.StackID = "test-stack-id"
+
+		t.Setenv("BP_TOMCAT_ACCESS_LOGGING_SUPPORT_ENABLED", "false")
+		t.Setenv("BP_TOMCAT_LIFECYCLE_SUPPORT_ENABLED", "false")
+		t.Setenv("BP_TOMCAT_LOGGING_SUPPORT_ENABLED", "false")
+
+		result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads"}))
+
+		base := result.Layers[2].(tomcat.Base)
+		Expect(base.AccessLoggingDependency).To(BeNil())
+		Expect(base.LifecycleDependency).To(BeNil())
+		Expect(base.LoggingDependency).To(BeNil())
+
+		Expect(result.BOM.Entries).To(HaveLen(2))
+		Expect(result.BOM.Entries[0].Name).To(Equal("tomcat"))
+		Expect(result.BOM.Entries[1].Name).To(Equal("helper"))
+	})
+
+	it("contributes the binding-placeholders helper with $BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED", func() {
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+		ctx.Buildpack.Metadata = map[string]interface{}{
+			"dependencies": []map[string]interface{}{
+				{
+					"id":      "tomcat",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+				},
+			},
+		}
+		ctx.StackID = "test-stack-id"
+
+		t.Setenv("BP_TOMCAT_ACCESS_LOGGING_SUPPORT_ENABLED", "false")
+		t.Setenv("BP_TOMCAT_LIFECYCLE_SUPPORT_ENABLED", "false")
+		t.Setenv("BP_TOMCAT_LOGGING_SUPPORT_ENABLED", "false")
+		t.Setenv("BP_TOMCAT_BINDING_PROPERTY_SOURCE_ENABLED", "true")
+
+		result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"binding-placeholders", "connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads"}))
+	})
+
+	context("$BP_TOMCAT_APPCDS_ENABLED", func() {
+		var javaHome string
+
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{
+						"id":      "tomcat",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id", libpak.BionicTinyStackID},
+					},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+
+			var err error
+			javaHome, err = os.MkdirTemp("", "tomcat-java-home")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(javaHome, "release"), []byte("JAVA_VERSION=\"21.0.4\"\n"), 0644)).To(Succeed())
+
+			t.Setenv("BP_TOMCAT_ACCESS_LOGGING_SUPPORT_ENABLED", "false")
+			t.Setenv("BP_TOMCAT_APPCDS_ENABLED", "true")
+			t.Setenv("BP_TOMCAT_LIFECYCLE_SUPPORT_ENABLED", "false")
+			t.Setenv("BP_TOMCAT_LOGGING_SUPPORT_ENABLED", "false")
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(javaHome)).To(Succeed())
+		})
+
+		it("contributes AppCDS archive and helper", func() {
+			t.Setenv("JAVA_HOME", javaHome)
+
+			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(5))
+			Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"app-cds", "connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads"}))
+			Expect(result.Layers[2].(tomcat.Base).LayerContributor.ExpectedTypes.Cache).To(BeTrue())
+			Expect(result.Layers[4].Name()).To(Equal("app-cds"))
+			Expect(result.Layers[4].(tomcat.AppCDS).JavaHome).To(Equal(javaHome))
+			Expect(result.Layers[4].(tomcat.AppCDS).JavaVersion).To(Equal("21.0.4"))
+		})
+
+		it("fails without a JRE", func() {
+			_, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("$JAVA_HOME is not set")))
+		})
+
+		it("is skipped before Java 13", func() {
+			Expect(os.WriteFile(filepath.Join(javaHome, "release"), []byte("JAVA_VERSION=\"11.0.24\"\n"), 0644)).To(Succeed())
+			t.Setenv("JAVA_HOME", javaHome)
+
+			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(4))
+			Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).NotTo(ContainElement("app-cds"))
+			Expect(result.Layers[2].(tomcat.Base).LayerContributor.ExpectedTypes.Cache).To(BeFalse())
+		})
+
+		it("is skipped on Tiny", func() {
+			ctx.StackID = libpak.BionicTinyStackID
+
+			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(4))
+			Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).NotTo(ContainElement("app-cds"))
+			Expect(result.Layers[2].(tomcat.Base).LayerContributor.ExpectedTypes.Cache).To(BeFalse())
+		})
+	})
+
 	it("contributes Tomcat on Tiny", func() {
 		ctx.StackID = libpak.BionicTinyStackID
 
@@ -264,11 +514,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(result.Processes).To(ContainElement(expectedProcess))
 		}
 
-		Expect(result.Layers).To(HaveLen(3))
+		Expect(result.Layers).To(HaveLen(4))
 		Expect(result.Layers[0].Name()).To(Equal("tomcat"))
 		Expect(result.Layers[1].Name()).To(Equal("helper"))
-		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"access-logging-support"}))
+		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"access-logging-support", "connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads"}))
 		Expect(result.Layers[2].Name()).To(Equal("catalina-base"))
+		Expect(result.Layers[3].Name()).To(Equal("webapp-sbom"))
 
 		Expect(result.BOM.Entries).To(HaveLen(5))
 		Expect(result.BOM.Entries[0].Name).To(Equal("tomcat"))
@@ -341,6 +592,172 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("deployment descriptors", func() {
+		it.Before(func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{
+						"id":      "tomcat",
+						"version": "9.0.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat",
+						"version": "10.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat",
+						"version": "11.0.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-access-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-lifecycle-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "WEB-INF", "web.xml"),
+				[]byte(`<web-app xmlns="https://jakarta.ee/xml/ns/jakartaee" version="6.0"/>`), 0644)).To(Succeed())
+		})
+
+		it("selects version based on web.xml", func() {
+			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers[0].(tomcat.Home).LayerContributor.Dependency.Version).To(Equal("10.1.1"))
+		})
+
+		context("$BP_JVM_VERSION", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_JVM_VERSION", "8")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_JVM_VERSION")).To(Succeed())
+			})
+
+			it("fails when Java is older than Tomcat requires", func() {
+				_, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("Tomcat 10.1.1 requires Java 11 or later, but BP_JVM_VERSION is 8")))
+			})
+		})
+
+		context("$JAVA_HOME", func() {
+			var javaHome string
+
+			it.Before(func() {
+				var err error
+				javaHome, err = os.MkdirTemp("", "tomcat-java-home")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.WriteFile(filepath.Join(javaHome, "release"), []byte("JAVA_VERSION=\"1.8.0_422\"\n"), 0644)).To(Succeed())
+				t.Setenv("JAVA_HOME", javaHome)
+			})
+
+			it.After(func() {
+				Expect(os.RemoveAll(javaHome)).To(Succeed())
+			})
+
+			it("fails when the resolved JRE is older than Tomcat requires", func() {
+				_, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("Tomcat 10.1.1 requires Java 11 or later, but the JRE in " + javaHome + " is 1.8.0_422")))
+			})
+
+			it("fails when the resolved JRE cannot be checked", func() {
+				Expect(os.Remove(filepath.Join(javaHome, "release"))).To(Succeed())
+
+				_, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).To(MatchError(ContainSubstring("unable to check the Java version Tomcat 10.1.1 runs on")))
+			})
+		})
+
+		context("$BP_TOMCAT_VERSION", func() {
+			it.Before(func() {
+				Expect(os.Setenv("BP_TOMCAT_VERSION", "11.*")).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Unsetenv("BP_TOMCAT_VERSION")).To(Succeed())
+			})
+
+			it("does not override $BP_TOMCAT_VERSION", func() {
+				result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers[0].(tomcat.Home).LayerContributor.Dependency.Version).To(Equal("11.0.1"))
+			})
+		})
+	})
+
+	context("extra libraries", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{
+						"id":      "tomcat",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-access-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-lifecycle-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-extra-lib-postgresql",
+						"version": "42.1.0",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+
+			t.Setenv("BP_TOMCAT_EXTRA_LIBS", "test-uri/valve.jar=a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90")
+		})
+
+		it("contributes extra libraries from buildpack.toml and $BP_TOMCAT_EXTRA_LIBS", func() {
+			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			base := result.Layers[2].(tomcat.Base)
+			Expect(base.ExtraLibraries).To(HaveLen(2))
+			Expect(base.ExtraLibraries[0].ID).To(Equal("tomcat-extra-lib-postgresql"))
+			Expect(base.ExtraLibraries[1].ID).To(Equal("tomcat-extra-lib-valve"))
+
+			var names []string
+			for _, e := range result.BOM.Entries {
+				names = append(names, e.Name)
+			}
+			Expect(names).To(ContainElements("tomcat-extra-lib-postgresql", "tomcat-extra-lib-valve"))
+		})
+	})
+
 	context("$BP_TOMCAT_EXT_CONF_URI", func() {
 		it.Before(func() {
 			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
@@ -380,7 +797,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(4))
 			Expect(result.Layers[2].(tomcat.Base).ExternalConfigurationDependency).To(Equal(&libpak.BuildpackDependency{
 				ID:      "tomcat-external-configuration",
 				Name:    "Tomcat External Configuration",
@@ -399,7 +816,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(4))
 			version := result.Layers[2].(tomcat.Base).ExternalConfigurationDependency.Version
 			Expect(time.Parse(time.RFC3339, version)).NotTo(BeNil())
 		})
@@ -410,7 +827,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(4))
 			Expect(result.Layers[2].(tomcat.Base).ExternalConfigurationDependency).To(Equal(&libpak.BuildpackDependency{
 				ID:      "tomcat-external-configuration",
 				Name:    "Tomcat External Configuration",
@@ -428,7 +845,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(result.Layers).To(HaveLen(3))
+			Expect(result.Layers).To(HaveLen(4))
 			Expect(result.Layers[2].(tomcat.Base).ExternalConfigurationDependency).To(Equal(&libpak.BuildpackDependency{
 				ID:      "tomcat-external-configuration",
 				Name:    "Tomcat External Configuration",
@@ -442,6 +859,92 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 	})
 
+	context("$BP_TOMCAT_ADVISORY_FAIL_SEVERITY", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "WEB-INF"), 0755)).To(Succeed())
+
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{
+						"id":      "tomcat",
+						"name":    "Apache Tomcat",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-access-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-lifecycle-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+					{
+						"id":      "tomcat-logging-support",
+						"version": "1.1.1",
+						"stacks":  []interface{}{"test-stack-id"},
+					},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+			ctx.Platform.Bindings = libcnb.Bindings{
+				{
+					Name: "test-advisories",
+					Type: "tomcat-advisories",
+					Secret: map[string]string{"advisories.toml": `
+[[advisories]]
+  id = "TEST-0001"
+  dependency = "tomcat"
+  severity = "high"
+  affected = ["<2.0.0"]
+`},
+				},
+			}
+
+			t.Setenv("BP_TOMCAT_ADVISORY_FAIL_SEVERITY", "high")
+		})
+
+		it.After(func() {
+			ctx.Platform.Bindings = nil
+		})
+
+		it("fails when the resolved Tomcat is affected by an advisory", func() {
+			_, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("Apache Tomcat 1.1.1 is affected by TEST-0001 (high)")))
+		})
+
+		it("fails when the OpenTelemetry Java agent is affected by an advisory", func() {
+			ctx.Buildpack.Metadata["dependencies"] = append(ctx.Buildpack.Metadata["dependencies"].([]map[string]interface{}),
+				map[string]interface{}{
+					"id":      "opentelemetry-javaagent",
+					"name":    "OpenTelemetry Java Agent",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+				})
+			ctx.Platform.Bindings[0].Secret["advisories.toml"] = `
+[[advisories]]
+  id = "TEST-0002"
+  dependency = "opentelemetry-javaagent"
+  severity = "high"
+  affected = ["<2.0.0"]
+`
+			t.Setenv("BP_TOMCAT_OTEL_ENABLED", "true")
+
+			_, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring("OpenTelemetry Java Agent 1.1.1 is affected by TEST-0002 (high)")))
+		})
+
+		it("rejects an invalid severity without advisories", func() {
+			ctx.Platform.Bindings = nil
+			t.Setenv("BP_TOMCAT_ADVISORY_FAIL_SEVERITY", "hihg")
+
+			_, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+			Expect(err).To(MatchError(ContainSubstring(`invalid severity threshold "hihg"`)))
+		})
+	})
+
 	it("returns default context path", func() {
 		Expect(tomcat.Build{SBOMScanner: &sbomScanner}.ContextPath(libpak.ConfigurationResolver{})).To(Equal("ROOT"))
 	})
@@ -461,7 +964,9 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 	})
 
 	it("contributes Tomcat with war files", func() {
-		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "test.war"), []byte(`test`), 0644)).To(Succeed())
+		in, err := os.ReadFile(filepath.Join("testdata", "warfiles", "api.war"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "test.war"), in, 0644)).To(Succeed())
 
 		ctx.Buildpack.Metadata = map[string]interface{}{
 			"dependencies": []map[string]interface{}{
@@ -507,11 +1012,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			libcnb.Process{Type: "web", Command: "sh", Arguments: []string{"tomcat/bin/catalina.sh", "run"}, Direct: true, Default: true},
 		))
 
-		Expect(result.Layers).To(HaveLen(3))
+		Expect(result.Layers).To(HaveLen(4))
 		Expect(result.Layers[0].Name()).To(Equal("tomcat"))
 		Expect(result.Layers[1].Name()).To(Equal("helper"))
-		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"access-logging-support"}))
+		Expect(result.Layers[1].(libpak.HelperLayerContributor).Names).To(Equal([]string{"access-logging-support", "connector-sizing", "placeholder-audit", "realm", "remote-ip", "virtual-threads"}))
 		Expect(result.Layers[2].Name()).To(Equal("catalina-base"))
+		Expect(result.Layers[3].Name()).To(Equal("webapp-sbom"))
 
 		Expect(result.BOM.Entries).To(HaveLen(5))
 		Expect(result.BOM.Entries[0].Name).To(Equal("tomcat"))
@@ -530,7 +1036,66 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		Expect(result.BOM.Entries[4].Build).To(BeFalse())
 		Expect(result.BOM.Entries[4].Launch).To(BeTrue())
 
+		Expect(filepath.Join(ctx.Application.Path, "test.war")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(ctx.Application.Path, "test", "WEB-INF", "web.xml")).To(BeARegularFile())
+
 		sbomScanner.AssertCalled(t, "ScanLaunch", ctx.Application.Path, libcnb.SyftJSON, libcnb.CycloneDXJSON)
 	})
 
+	it("contributes Tomcat with a build output war file", func() {
+		in, err := os.ReadFile(filepath.Join("testdata", "warfiles", "api.war"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "target"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "target", "api-1.0.0.war"), in, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(ctx.Application.Path, "pom.xml"), []byte{}, 0644)).To(Succeed())
+
+		ctx.Buildpack.Metadata = map[string]interface{}{
+			"dependencies": []map[string]interface{}{
+				{
+					"id":      "tomcat",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+					"purl":    "pkg:generic/tomcat@1.1.1",
+					"cpes":    "cpe:2.3:a:apache:tomcat:1.1.1:*:*:*:*:*:*:*",
+				},
+				{
+					"id":      "tomcat-access-logging-support",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+					"purl":    "pkg:generic/tomcat-access-logging-support@1.1.1",
+					"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:1.1.1:*:*:*:*:*:*:*",
+				},
+				{
+					"id":      "tomcat-lifecycle-support",
+					"version": "1.1.1",
+					"stacks":  []interface{}{"test-stack-id"},
+					"purl":    "pkg:generic/tomcat-lifecycle-logging-support@1.1.1",
+					"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-lifecycle-logging-support:1.1.1:*:*:*:*:*:*:*",
+				},
+				{
+					"id":      "tomcat-logging-support",
+					"version": "1.1.1",
+					"uri":     "https://example.com/releases/tomcat-logging-support-1.1.1.RELEASE.jar",
+					"stacks":  []interface{}{"test-stack-id"},
+					"purl":    "pkg:generic/tomcat-logging-support@1.1.1",
+					"cpes":    "cpe:2.3:a:cloudfoundry:tomcat-logging-support:1.1.1:*:*:*:*:*:*:*",
+				},
+			},
+		}
+		ctx.StackID = "test-stack-id"
+
+		result, err := tomcat.Build{SBOMScanner: &sbomScanner}.Build(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(result.Layers).To(HaveLen(4))
+		Expect(result.Layers[2].Name()).To(Equal("catalina-base"))
+		Expect(result.Layers[2].(tomcat.Base).WarFilesExist).To(BeFalse())
+
+		Expect(result.Layers[2].(tomcat.Base).ApplicationPath).To(Equal(filepath.Join(ctx.Application.Path, ".tomcat-webapps", "api-1.0.0")))
+
+		Expect(filepath.Join(ctx.Application.Path, "target", "api-1.0.0.war")).To(BeARegularFile())
+		Expect(filepath.Join(ctx.Application.Path, "pom.xml")).To(BeARegularFile())
+		Expect(filepath.Join(ctx.Application.Path, ".tomcat-webapps", "api-1.0.0", "WEB-INF", "web.xml")).To(BeARegularFile())
+	})
+
 }
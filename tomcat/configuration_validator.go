@@ -0,0 +1,243 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// ConfigurationValidator checks the final Tomcat configuration in CATALINA_BASE before it is shipped in an image.
+type ConfigurationValidator struct {
+	AdditionalClassPath []string
+	CatalinaBase        string
+	CatalinaHome        string
+	Logger              bard.Logger
+}
+
+// ConfigurationProblem describes a single problem found in a configuration file.
+type ConfigurationProblem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (c ConfigurationProblem) String() string {
+	if c.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", c.File, c.Line, c.Message)
+	}
+	return fmt.Sprintf("%s: %s", c.File, c.Message)
+}
+
+// ConfigurationError is returned when one or more configuration problems are found.
+type ConfigurationError struct {
+	Problems []ConfigurationProblem
+}
+
+func (c ConfigurationError) Error() string {
+	var s []string
+	for _, p := range c.Problems {
+		s = append(s, p.String())
+	}
+	return fmt.Sprintf("invalid Tomcat configuration\n%s", strings.Join(s, "\n"))
+}
+
+type configurationElement struct {
+	Attributes map[string]string
+	Line       int
+	Name       string
+}
+
+type configurationPort struct {
+	Element string
+	File    string
+	Line    int
+}
+
+func (c ConfigurationValidator) Validate() error {
+	var problems []ConfigurationProblem
+
+	classes, err := c.availableClasses()
+	if err != nil {
+		return fmt.Errorf("unable to index available classes\n%w", err)
+	}
+	if len(classes) == 0 {
+		c.Logger.Bodyf("No classes found in %s or %s, skipping className validation", c.CatalinaHome, c.CatalinaBase)
+	}
+
+	ports := map[string]configurationPort{}
+
+	for _, name := range []string{"server.xml", "context.xml", "web.xml"} {
+		file := filepath.Join(c.CatalinaBase, "conf", name)
+
+		elements, p, err := c.parse(file)
+		if err != nil {
+			return err
+		}
+		if p != nil {
+			problems = append(problems, *p)
+			continue
+		}
+
+		if name == "web.xml" {
+			continue
+		}
+
+		for _, e := range elements {
+			if n, ok := e.Attributes["className"]; ok && len(classes) > 0 && !strings.Contains(n, "${") {
+				if _, found := classes[n]; !found {
+					problems = append(problems, ConfigurationProblem{
+						File:    file,
+						Line:    e.Line,
+						Message: fmt.Sprintf("%s className %s not found in CATALINA_HOME or CATALINA_BASE", e.Name, n),
+					})
+				}
+			}
+
+			if e.Name != "Server" && e.Name != "Connector" {
+				continue
+			}
+
+			port, ok := e.Attributes["port"]
+			if !ok || port == "-1" || port == "0" || strings.Contains(port, "${") {
+				continue
+			}
+
+			if existing, ok := ports[port]; ok {
+				problems = append(problems, ConfigurationProblem{
+					File:    file,
+					Line:    e.Line,
+					Message: fmt.Sprintf("%s port %s collides with %s at %s:%d", e.Name, port, existing.Element, existing.File, existing.Line),
+				})
+				continue
+			}
+			ports[port] = configurationPort{Element: e.Name, File: file, Line: e.Line}
+		}
+	}
+
+	if len(problems) > 0 {
+		return ConfigurationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func (c ConfigurationValidator) parse(file string) ([]configurationElement, *ConfigurationProblem, error) {
+	in, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, &ConfigurationProblem{File: file, Message: "file does not exist"}, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("unable to open %s\n%w", file, err)
+	}
+	defer in.Close()
+
+	var (
+		decoder  = xml.NewDecoder(in)
+		elements []configurationElement
+		root     bool
+	)
+
+	for {
+		line, _ := decoder.InputPos()
+
+		t, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			var syntaxError *xml.SyntaxError
+			if errors.As(err, &syntaxError) {
+				return nil, &ConfigurationProblem{File: file, Line: syntaxError.Line, Message: syntaxError.Msg}, nil
+			}
+			return nil, nil, fmt.Errorf("unable to parse %s\n%w", file, err)
+		}
+
+		if s, ok := t.(xml.StartElement); ok {
+			root = true
+			e := configurationElement{Attributes: map[string]string{}, Line: line, Name: s.Name.Local}
+			for _, a := range s.Attr {
+				e.Attributes[a.Name.Local] = a.Value
+			}
+			elements = append(elements, e)
+		}
+	}
+
+	if !root {
+		return nil, &ConfigurationProblem{File: file, Message: "no root element found"}, nil
+	}
+
+	return elements, nil, nil
+}
+
+func (c ConfigurationValidator) availableClasses() (map[string]struct{}, error) {
+	var jars []string
+	for _, dir := range []string{
+		filepath.Join(c.CatalinaHome, "bin"),
+		filepath.Join(c.CatalinaHome, "lib"),
+		filepath.Join(c.CatalinaBase, "bin"),
+		filepath.Join(c.CatalinaBase, "lib"),
+	} {
+		j, err := filepath.Glob(filepath.Join(dir, "*.jar"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to list jars in %s\n%w", dir, err)
+		}
+		jars = append(jars, j...)
+	}
+
+	for _, p := range c.AdditionalClassPath {
+		if fi, err := os.Stat(p); err != nil {
+			continue
+		} else if fi.IsDir() {
+			j, err := filepath.Glob(filepath.Join(p, "*.jar"))
+			if err != nil {
+				return nil, fmt.Errorf("unable to list jars in %s\n%w", p, err)
+			}
+			jars = append(jars, j...)
+		} else if strings.HasSuffix(p, ".jar") {
+			jars = append(jars, p)
+		}
+	}
+
+	classes := map[string]struct{}{}
+	for _, jar := range jars {
+		z, err := zip.OpenReader(jar)
+		if err != nil {
+			c.Logger.Debugf("Unable to open %s: %s", jar, err)
+			continue
+		}
+
+		for _, f := range z.File {
+			if strings.HasSuffix(f.Name, ".class") {
+				n := strings.TrimSuffix(f.Name, ".class")
+				classes[strings.ReplaceAll(n, "/", ".")] = struct{}{}
+			}
+		}
+
+		if err := z.Close(); err != nil {
+			return nil, fmt.Errorf("unable to close %s\n%w", jar, err)
+		}
+	}
+
+	return classes, nil
+}
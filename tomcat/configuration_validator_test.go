@@ -0,0 +1,151 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testConfigurationValidator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		base string
+		home string
+		v    tomcat.ConfigurationValidator
+	)
+
+	writeJar := func(path string, classes ...string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		out, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer out.Close()
+
+		z := zip.NewWriter(out)
+		for _, c := range classes {
+			_, err := z.Create(c)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(z.Close()).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		base, err = os.MkdirTemp("", "configuration-validator-base")
+		Expect(err).NotTo(HaveOccurred())
+
+		home, err = os.MkdirTemp("", "configuration-validator-home")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(base, "conf"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(base, "conf", "context.xml"), []byte("<Context/>"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(base, "conf", "web.xml"), []byte("<web-app/>"), 0644)).To(Succeed())
+
+		writeJar(filepath.Join(home, "lib", "catalina.jar"), "org/apache/catalina/valves/RemoteIpValve.class")
+		writeJar(filepath.Join(base, "lib", "support.jar"), "org/cloudfoundry/tomcat/lifecycle/Listener.class")
+
+		v = tomcat.ConfigurationValidator{CatalinaBase: base, CatalinaHome: home}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(base)).To(Succeed())
+		Expect(os.RemoveAll(home)).To(Succeed())
+	})
+
+	it("passes valid configuration", func() {
+		Expect(os.WriteFile(filepath.Join(base, "conf", "server.xml"), []byte(`<Server port='-1'>
+  <Service name='Catalina'>
+    <Connector port='8080'/>
+    <Engine defaultHost='localhost' name='Catalina'>
+      <Valve className='org.apache.catalina.valves.RemoteIpValve'/>
+      <Host name='localhost'>
+        <Listener className='org.cloudfoundry.tomcat.lifecycle.Listener'/>
+      </Host>
+    </Engine>
+  </Service>
+</Server>`), 0644)).To(Succeed())
+
+		Expect(v.Validate()).To(Succeed())
+	})
+
+	it("reports malformed XML with file and line", func() {
+		file := filepath.Join(base, "conf", "server.xml")
+		Expect(os.WriteFile(file, []byte("<Server>\n  <Service>\n</Server>"), 0644)).To(Succeed())
+
+		err := v.Validate()
+		Expect(err).To(BeAssignableToTypeOf(tomcat.ConfigurationError{}))
+		Expect(err.(tomcat.ConfigurationError).Problems).To(HaveLen(1))
+		Expect(err.(tomcat.ConfigurationError).Problems[0].File).To(Equal(file))
+		Expect(err.(tomcat.ConfigurationError).Problems[0].Line).To(Equal(3))
+	})
+
+	it("reports missing files", func() {
+		Expect(os.Remove(filepath.Join(base, "conf", "web.xml"))).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(base, "conf", "server.xml"), []byte("<Server port='-1'/>"), 0644)).To(Succeed())
+
+		Expect(v.Validate()).To(MatchError(ContainSubstring(fmt.Sprintf("%s: file does not exist", filepath.Join(base, "conf", "web.xml")))))
+	})
+
+	it("reports unknown classNames", func() {
+		file := filepath.Join(base, "conf", "server.xml")
+		Expect(os.WriteFile(file, []byte(`<Server port='-1'>
+  <Service name='Catalina'>
+    <Engine defaultHost='localhost' name='Catalina'>
+      <Valve className='org.example.MissingValve'/>
+      <Valve className='${custom.valve}'/>
+    </Engine>
+  </Service>
+</Server>`), 0644)).To(Succeed())
+
+		Expect(v.Validate()).To(MatchError(ContainSubstring(
+			fmt.Sprintf("%s:4: Valve className org.example.MissingValve not found in CATALINA_HOME or CATALINA_BASE", file))))
+	})
+
+	it("finds classes on additional class path", func() {
+		writeJar(filepath.Join(base, "additional", "additional.jar"), "org/example/AdditionalValve.class")
+		v.AdditionalClassPath = []string{filepath.Join(base, "additional")}
+
+		Expect(os.WriteFile(filepath.Join(base, "conf", "server.xml"), []byte(`<Server port='-1'>
+  <Valve className='org.example.AdditionalValve'/>
+</Server>`), 0644)).To(Succeed())
+
+		Expect(v.Validate()).To(Succeed())
+	})
+
+	it("reports colliding ports", func() {
+		file := filepath.Join(base, "conf", "server.xml")
+		Expect(os.WriteFile(file, []byte(`<Server port='8080'>
+  <Service name='Catalina'>
+    <Connector port='8080'/>
+    <Connector port='${PORT}'/>
+  </Service>
+</Server>`), 0644)).To(Succeed())
+
+		Expect(v.Validate()).To(MatchError(ContainSubstring(
+			fmt.Sprintf("%s:3: Connector port 8080 collides with Server at %s:1", file, file))))
+	})
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
+)
+
+// ConnectorSizing adds maxThreads, acceptCount, maxConnections and useVirtualThreads placeholders to the Connectors in
+// $CATALINA_BASE/conf/server.xml that do not declare them, so the connector-sizing and virtual-threads helpers can
+// configure them at launch with system properties.  The placeholders default to Tomcat's defaults.  maxThreads and
+// useVirtualThreads are not added to Connectors that use an Executor.
+type ConnectorSizing struct {
+	Logger bard.Logger
+}
+
+func (c ConnectorSizing) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	file := filepath.Join(layer.Path, "conf", "server.xml")
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	c.Logger.Header(color.BlueString("Tomcat Connector sizing"))
+	c.Logger.Body("Adding maxThreads, acceptCount, maxConnections and useVirtualThreads placeholders to Connectors in server.xml")
+
+	b = util.Element("Connector").ReplaceAllFunc(b, func(connector []byte) []byte {
+		if util.HasAttribute(string(connector), "executor") {
+			port, _ := util.Attribute(string(connector), "port")
+			c.Logger.Bodyf("Connector on port %s uses an Executor, its threads are not sized and are not virtual", port)
+
+			return []byte(util.AddAttributes(string(connector), [][2]string{
+				{"acceptCount", "${tomcat.connector.acceptCount:-100}"},
+				{"maxConnections", "${tomcat.connector.maxConnections:-8192}"},
+			}))
+		}
+
+		return []byte(util.AddAttributes(string(connector), [][2]string{
+			{"maxThreads", "${tomcat.connector.maxThreads:-200}"},
+			{"acceptCount", "${tomcat.connector.acceptCount:-100}"},
+			{"maxConnections", "${tomcat.connector.maxConnections:-8192}"},
+			{"useVirtualThreads", "${tomcat.connector.useVirtualThreads:-false}"},
+		}))
+	})
+
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return nil, fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return nil, nil
+}
+
+func (ConnectorSizing) Name() string {
+	return "connector sizing"
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testConnectorSizing(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layer libcnb.Layer
+	)
+
+	it.Before(func() {
+		var err error
+		layer.Path, err = os.MkdirTemp("", "connector-sizing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(layer.Path, "conf"), 0755)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layer.Path)).To(Succeed())
+	})
+
+	it("adds placeholders to Connectors", func() {
+		Expect(os.WriteFile(filepath.Join(layer.Path, "conf", "server.xml"), []byte(`<Service name='Catalina'>
+    <Connector port='8080' bindOnInit='false' connectionTimeout='20000'/>
+    <Connector port='8443' acceptCount='50' executor='tomcatThreadPool'/>
+</Service>
+`), 0644)).To(Succeed())
+
+		b := &bytes.Buffer{}
+		Expect(tomcat.ConnectorSizing{Logger: bard.NewLogger(b)}.Contribute(layer)).To(BeNil())
+		Expect(b.String()).To(ContainSubstring("Connector on port 8443 uses an Executor"))
+
+		Expect(os.ReadFile(filepath.Join(layer.Path, "conf", "server.xml"))).To(Equal([]byte(`<Service name='Catalina'>
+    <Connector maxThreads='${tomcat.connector.maxThreads:-200}' acceptCount='${tomcat.connector.acceptCount:-100}' maxConnections='${tomcat.connector.maxConnections:-8192}' useVirtualThreads='${tomcat.connector.useVirtualThreads:-false}' port='8080' bindOnInit='false' connectionTimeout='20000'/>
+    <Connector maxConnections='${tomcat.connector.maxConnections:-8192}' port='8443' acceptCount='50' executor='tomcatThreadPool'/>
+</Service>
+`)))
+	})
+
+	it("ignores a missing server.xml", func() {
+		Expect(tomcat.ConnectorSizing{}.Contribute(layer)).To(BeNil())
+	})
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// ContextName converts a context path such as /api/v1 to the name Tomcat uses for its directory in webapps, api#v1.
+// The empty path and / are the ROOT context.
+func ContextName(contextPath string) string {
+	s := strings.TrimPrefix(contextPath, "/")
+	s = strings.TrimSuffix(s, "/")
+	s = strings.ReplaceAll(s, "/", "#")
+
+	if s == "" {
+		return "ROOT"
+	}
+	return s
+}
+
+// ContextURL converts the name of a context directory in webapps to the URL path it is served at.
+func ContextURL(contextName string) string {
+	if contextName == "ROOT" {
+		return "/"
+	}
+	return "/" + strings.ReplaceAll(contextName, "#", "/") + "/"
+}
+
+// StaticContext mounts a directory of the application as its own context.
+type StaticContext struct {
+	ContextName string
+	Directory   string
+}
+
+// ParseStaticContexts parses a comma separated list of <directory>[=<context-path>] entries.  When the context path is
+// omitted, the directory is mounted at a context path with the same name.
+func ParseStaticContexts(s string) ([]StaticContext, error) {
+	var contexts []StaticContext
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		dir, contextPath, ok := strings.Cut(entry, "=")
+		if !ok {
+			contextPath = dir
+		}
+
+		dir = filepath.Clean(strings.TrimSpace(dir))
+		if filepath.IsAbs(dir) || dir == "." || strings.HasPrefix(dir, "..") {
+			return nil, fmt.Errorf("static context directory %q must be a subdirectory of the application", dir)
+		}
+
+		contexts = append(contexts, StaticContext{ContextName: ContextName(strings.TrimSpace(contextPath)), Directory: dir})
+	}
+
+	return contexts, nil
+}
+
+// Contexts contributes contexts alongside the application when it is mounted at a single context path: a ROOT context
+// that redirects to, or serves a landing page for, the application and additional static contexts.
+type Contexts struct {
+	ApplicationPath string
+	ContextName     string
+	Logger          bard.Logger
+	RootPage        string
+	RootRedirect    bool
+	StaticContexts  []StaticContext
+}
+
+func (c Contexts) Contribute(webapps string) error {
+	names := map[string]string{c.ContextName: "the application"}
+
+	if c.RootRedirect && c.RootPage != "" {
+		return fmt.Errorf("BP_TOMCAT_ROOT_REDIRECT and BP_TOMCAT_ROOT_PAGE cannot both be set")
+	}
+
+	if c.RootRedirect || c.RootPage != "" {
+		if c.ContextName == "ROOT" {
+			c.Logger.Body("WARNING: the application is mounted at ROOT, ignoring BP_TOMCAT_ROOT_REDIRECT and BP_TOMCAT_ROOT_PAGE")
+		} else if err := c.contributeRoot(filepath.Join(webapps, "ROOT")); err != nil {
+			return err
+		} else {
+			names["ROOT"] = "the ROOT context"
+		}
+	}
+
+	for _, s := range c.StaticContexts {
+		if owner, ok := names[s.ContextName]; ok {
+			return fmt.Errorf("static context %s from %s conflicts with %s", ContextURL(s.ContextName), s.Directory, owner)
+		}
+		names[s.ContextName] = s.Directory
+
+		source := filepath.Join(c.ApplicationPath, s.Directory)
+		if fi, err := os.Stat(source); err != nil {
+			return fmt.Errorf("unable to stat static context directory %s\n%w", source, err)
+		} else if !fi.IsDir() {
+			return fmt.Errorf("static context %s is not a directory", source)
+		}
+
+		c.Logger.Headerf("Mounting %s at %s", s.Directory, ContextURL(s.ContextName))
+		file := filepath.Join(webapps, s.ContextName)
+		if err := os.Symlink(source, file); err != nil {
+			return fmt.Errorf("unable to create symlink from %s to %s\n%w", source, file, err)
+		}
+	}
+
+	return nil
+}
+
+func (c Contexts) contributeRoot(root string) error {
+	if c.RootRedirect {
+		c.Logger.Headerf("Redirecting / to %s", ContextURL(c.ContextName))
+
+		file := filepath.Join(root, "META-INF", "context.xml")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(file), err)
+		}
+		if err := os.WriteFile(file, []byte(`<Context>
+    <Valve className='org.apache.catalina.valves.rewrite.RewriteValve'/>
+</Context>
+`), 0644); err != nil {
+			return fmt.Errorf("unable to write file %s\n%w", file, err)
+		}
+
+		file = filepath.Join(root, "WEB-INF", "rewrite.config")
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(file), err)
+		}
+		if err := os.WriteFile(file, []byte(fmt.Sprintf("RewriteRule ^/?$ %s [R=302,L]\n", ContextURL(c.ContextName))), 0644); err != nil {
+			return fmt.Errorf("unable to write file %s\n%w", file, err)
+		}
+
+		return nil
+	}
+
+	page := filepath.Clean(c.RootPage)
+	if filepath.IsAbs(page) || page == "." || page == ".." || strings.HasPrefix(page, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("root page %q must be a file in the application", c.RootPage)
+	}
+
+	source := filepath.Join(c.ApplicationPath, page)
+	if _, err := os.Stat(source); err != nil {
+		return fmt.Errorf("unable to stat root page %s\n%w", source, err)
+	}
+
+	c.Logger.Headerf("Serving %s at /", c.RootPage)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", root, err)
+	}
+
+	file := filepath.Join(root, "index.html")
+	if err := os.Symlink(source, file); err != nil {
+		return fmt.Errorf("unable to create symlink from %s to %s\n%w", source, file, err)
+	}
+
+	return nil
+}
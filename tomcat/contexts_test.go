@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testContexts(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		app      string
+		contexts tomcat.Contexts
+		webapps  string
+	)
+
+	it.Before(func() {
+		var err error
+
+		app, err = os.MkdirTemp("", "contexts-application")
+		Expect(err).NotTo(HaveOccurred())
+
+		webapps, err = os.MkdirTemp("", "contexts-webapps")
+		Expect(err).NotTo(HaveOccurred())
+
+		contexts = tomcat.Contexts{ApplicationPath: app, ContextName: "api#v1"}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(app)).To(Succeed())
+		Expect(os.RemoveAll(webapps)).To(Succeed())
+	})
+
+	it("converts context paths", func() {
+		Expect(tomcat.ContextName("")).To(Equal("ROOT"))
+		Expect(tomcat.ContextName("/")).To(Equal("ROOT"))
+		Expect(tomcat.ContextName("/api/v1/")).To(Equal("api#v1"))
+		Expect(tomcat.ContextURL("ROOT")).To(Equal("/"))
+		Expect(tomcat.ContextURL("api#v1")).To(Equal("/api/v1/"))
+	})
+
+	it("parses static contexts", func() {
+		Expect(tomcat.ParseStaticContexts("public, docs=/help/v1,")).To(Equal([]tomcat.StaticContext{
+			{ContextName: "public", Directory: "public"},
+			{ContextName: "help#v1", Directory: "docs"},
+		}))
+
+		_, err := tomcat.ParseStaticContexts("../secrets")
+		Expect(err).To(MatchError(`static context directory "../secrets" must be a subdirectory of the application`))
+	})
+
+	it("contributes nothing by default", func() {
+		Expect(contexts.Contribute(webapps)).To(Succeed())
+		Expect(os.ReadDir(webapps)).To(BeEmpty())
+	})
+
+	it("contributes ROOT redirect", func() {
+		contexts.RootRedirect = true
+
+		Expect(contexts.Contribute(webapps)).To(Succeed())
+		Expect(filepath.Join(webapps, "ROOT", "META-INF", "context.xml")).To(BeARegularFile())
+		Expect(os.ReadFile(filepath.Join(webapps, "ROOT", "WEB-INF", "rewrite.config"))).
+			To(Equal([]byte("RewriteRule ^/?$ /api/v1/ [R=302,L]\n")))
+	})
+
+	it("contributes ROOT landing page", func() {
+		Expect(os.WriteFile(filepath.Join(app, "landing.html"), []byte("hello"), 0644)).To(Succeed())
+		contexts.RootPage = "landing.html"
+
+		Expect(contexts.Contribute(webapps)).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(webapps, "ROOT", "index.html"))).To(Equal([]byte("hello")))
+	})
+
+	it("fails with a landing page outside the application", func() {
+		contexts.RootPage = "../landing.html"
+		Expect(contexts.Contribute(webapps)).To(MatchError(`root page "../landing.html" must be a file in the application`))
+
+		contexts.RootPage = "/etc/passwd"
+		Expect(contexts.Contribute(webapps)).To(MatchError(`root page "/etc/passwd" must be a file in the application`))
+	})
+
+	it("does not contribute ROOT when the application is ROOT", func() {
+		contexts.ContextName = "ROOT"
+		contexts.RootRedirect = true
+
+		Expect(contexts.Contribute(webapps)).To(Succeed())
+		Expect(filepath.Join(webapps, "ROOT")).NotTo(BeAnExistingFile())
+	})
+
+	it("fails with both ROOT redirect and landing page", func() {
+		contexts.RootRedirect = true
+		contexts.RootPage = "landing.html"
+
+		Expect(contexts.Contribute(webapps)).To(MatchError("BP_TOMCAT_ROOT_REDIRECT and BP_TOMCAT_ROOT_PAGE cannot both be set"))
+	})
+
+	it("contributes static contexts", func() {
+		Expect(os.MkdirAll(filepath.Join(app, "public"), 0755)).To(Succeed())
+		contexts.StaticContexts = []tomcat.StaticContext{{ContextName: "assets", Directory: "public"}}
+
+		Expect(contexts.Contribute(webapps)).To(Succeed())
+		Expect(os.Readlink(filepath.Join(webapps, "assets"))).To(Equal(filepath.Join(app, "public")))
+	})
+
+	it("fails with conflicting static contexts", func() {
+		Expect(os.MkdirAll(filepath.Join(app, "public"), 0755)).To(Succeed())
+		contexts.StaticContexts = []tomcat.StaticContext{{ContextName: "api#v1", Directory: "public"}}
+
+		Expect(contexts.Contribute(webapps)).To(MatchError("static context /api/v1/ from public conflicts with the application"))
+	})
+}
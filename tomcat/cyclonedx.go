@@ -0,0 +1,104 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type CycloneDXBOM struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    CycloneDXMetadata    `json:"metadata"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXMetadata struct {
+	Component CycloneDXComponent `json:"component"`
+	Tools     []CycloneDXTool    `json:"tools,omitempty"`
+}
+
+type CycloneDXTool struct {
+	Vendor string `json:"vendor,omitempty"`
+	Name   string `json:"name"`
+}
+
+type CycloneDXComponent struct {
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Type       string              `json:"type"`
+	Group      string              `json:"group,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Hashes     []CycloneDXHash     `json:"hashes,omitempty"`
+	Licenses   []CycloneDXLicense  `json:"licenses,omitempty"`
+	CPE        string              `json:"cpe,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+type CycloneDXHash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type CycloneDXLicense struct {
+	License CycloneDXLicenseChoice `json:"license"`
+}
+
+type CycloneDXLicenseChoice struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func NewCycloneDXBOM(name string, components []CycloneDXComponent) CycloneDXBOM {
+	if components == nil {
+		components = []CycloneDXComponent{}
+	}
+
+	return CycloneDXBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Component: CycloneDXComponent{Type: "application", Name: name},
+			Tools:     []CycloneDXTool{{Vendor: "Paketo", Name: "apache-tomcat"}},
+		},
+		Components: components,
+	}
+}
+
+func (c CycloneDXBOM) WriteTo(path string) error {
+	output, err := json.Marshal(&c)
+	if err != nil {
+		return fmt.Errorf("unable to marshal to JSON\n%w", err)
+	}
+
+	if err := os.WriteFile(path, output, 0644); err != nil {
+		return fmt.Errorf("unable to write to path %s\n%w", path, err)
+	}
+
+	return nil
+}
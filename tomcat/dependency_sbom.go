@@ -0,0 +1,166 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sbom"
+)
+
+// DependencySBOM writes layer SBOMs describing buildpack dependencies in each of the requested formats.
+type DependencySBOM struct {
+	Dependencies []libpak.BuildpackDependency
+	Logger       bard.Logger
+}
+
+func (d DependencySBOM) WriteTo(layer libcnb.Layer, formats ...libcnb.SBOMFormat) error {
+	for _, f := range formats {
+		path := layer.SBOMPath(f)
+
+		switch f {
+		case libcnb.SyftJSON:
+			var artifacts []sbom.SyftArtifact
+			for _, dep := range d.Dependencies {
+				a, err := dep.AsSyftArtifact()
+				if err != nil {
+					return fmt.Errorf("unable to get Syft Artifact for dependency: %s, \n%w", dep.Name, err)
+				}
+				artifacts = append(artifacts, a)
+			}
+
+			dep := sbom.NewSyftDependency(layer.Path, artifacts)
+			d.Logger.Debugf("Writing Syft SBOM at %s: %+v", path, dep)
+			if err := dep.WriteTo(path); err != nil {
+				return fmt.Errorf("unable to write SBOM\n%w", err)
+			}
+
+		case libcnb.CycloneDXJSON:
+			var components []CycloneDXComponent
+			for _, dep := range d.Dependencies {
+				components = append(components, d.cycloneDXComponent(dep))
+			}
+
+			bom := NewCycloneDXBOM(layer.Name, components)
+			d.Logger.Debugf("Writing CycloneDX SBOM at %s: %+v", path, bom)
+			if err := bom.WriteTo(path); err != nil {
+				return fmt.Errorf("unable to write SBOM\n%w", err)
+			}
+
+		case libcnb.SPDXJSON:
+			var packages []SPDXPackage
+			for _, dep := range d.Dependencies {
+				packages = append(packages, d.spdxPackage(dep))
+			}
+
+			doc := NewSPDXDocument(layer.Name, packages)
+			d.Logger.Debugf("Writing SPDX SBOM at %s: %+v", path, doc)
+			if err := doc.WriteTo(path); err != nil {
+				return fmt.Errorf("unable to write SBOM\n%w", err)
+			}
+
+		default:
+			return fmt.Errorf("unsupported SBOM format %s", f)
+		}
+	}
+
+	return nil
+}
+
+func (DependencySBOM) cycloneDXComponent(dep libpak.BuildpackDependency) CycloneDXComponent {
+	c := CycloneDXComponent{
+		BOMRef:  fmt.Sprintf("%s@%s", dep.ID, dep.Version),
+		Type:    "library",
+		Name:    dep.Name,
+		Version: dep.Version,
+		PURL:    dep.PURL,
+	}
+
+	if c.Name == "" {
+		c.Name = dep.ID
+	}
+
+	if len(dep.CPEs) > 0 {
+		c.CPE = dep.CPEs[0]
+	}
+
+	if dep.SHA256 != "" {
+		c.Hashes = append(c.Hashes, CycloneDXHash{Algorithm: "SHA-256", Content: dep.SHA256})
+	}
+
+	for _, l := range dep.Licenses {
+		c.Licenses = append(c.Licenses, CycloneDXLicense{License: CycloneDXLicenseChoice{ID: l.Type, URL: l.URI}})
+	}
+
+	return c
+}
+
+func (DependencySBOM) spdxPackage(dep libpak.BuildpackDependency) SPDXPackage {
+	p := SPDXPackage{
+		SPDXID:           SPDXID(dep.ID),
+		Name:             dep.Name,
+		VersionInfo:      dep.Version,
+		DownloadLocation: dep.URI,
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+	}
+
+	if p.Name == "" {
+		p.Name = dep.ID
+	}
+
+	if p.DownloadLocation == "" {
+		p.DownloadLocation = "NOASSERTION"
+	}
+
+	if dep.SHA256 != "" {
+		p.Checksums = append(p.Checksums, SPDXChecksum{Algorithm: "SHA256", ChecksumValue: dep.SHA256})
+	}
+
+	var licenses []string
+	for _, l := range dep.Licenses {
+		if l.Type != "" {
+			licenses = append(licenses, l.Type)
+		}
+	}
+	if len(licenses) > 0 {
+		p.LicenseDeclared = strings.Join(licenses, " AND ")
+	}
+
+	for _, cpe := range dep.CPEs {
+		p.ExternalRefs = append(p.ExternalRefs, SPDXExternalRef{
+			ReferenceCategory: "SECURITY",
+			ReferenceType:     "cpe23Type",
+			ReferenceLocator:  cpe,
+		})
+	}
+
+	if dep.PURL != "" {
+		p.ExternalRefs = append(p.ExternalRefs, SPDXExternalRef{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  dep.PURL,
+		})
+	}
+
+	return p
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testDependencySBOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx libcnb.BuildContext
+		d   tomcat.DependencySBOM
+	)
+
+	it.Before(func() {
+		var err error
+
+		ctx.Layers.Path, err = os.MkdirTemp("", "dependency-sbom-layers")
+		Expect(err).NotTo(HaveOccurred())
+
+		d = tomcat.DependencySBOM{Dependencies: []libpak.BuildpackDependency{
+			{
+				ID:       "tomcat-logging-support",
+				Name:     "Apache Tomcat Logging Support",
+				Version:  "3.4.0",
+				URI:      "https://localhost/stub-tomcat-logging-support.jar",
+				SHA256:   "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c",
+				PURL:     "pkg:generic/tomcat-logging-support@3.4.0",
+				CPEs:     []string{"cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.4.0:*:*:*:*:*:*:*"},
+				Licenses: []libpak.BuildpackDependencyLicense{{Type: "Apache-2.0", URI: "https://www.apache.org/licenses/"}},
+			},
+		}}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+	})
+
+	it("writes CycloneDX", func() {
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.WriteTo(layer, libcnb.CycloneDXJSON)).To(Succeed())
+
+		b, err := os.ReadFile(layer.SBOMPath(libcnb.CycloneDXJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		var bom tomcat.CycloneDXBOM
+		Expect(json.Unmarshal(b, &bom)).To(Succeed())
+		Expect(bom.BOMFormat).To(Equal("CycloneDX"))
+		Expect(bom.Metadata.Component.Name).To(Equal("test-layer"))
+		Expect(bom.Components).To(Equal([]tomcat.CycloneDXComponent{
+			{
+				BOMRef:   "tomcat-logging-support@3.4.0",
+				Type:     "library",
+				Name:     "Apache Tomcat Logging Support",
+				Version:  "3.4.0",
+				Hashes:   []tomcat.CycloneDXHash{{Algorithm: "SHA-256", Content: "e0a7e163cc9f1ffd41c8de3942c7c6b505090b7484c2ba9be846334e31c44a2c"}},
+				Licenses: []tomcat.CycloneDXLicense{{License: tomcat.CycloneDXLicenseChoice{ID: "Apache-2.0", URL: "https://www.apache.org/licenses/"}}},
+				CPE:      "cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.4.0:*:*:*:*:*:*:*",
+				PURL:     "pkg:generic/tomcat-logging-support@3.4.0",
+			},
+		}))
+	})
+
+	it("writes SPDX", func() {
+		t.Setenv("SOURCE_DATE_EPOCH", "0")
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.WriteTo(layer, libcnb.SPDXJSON)).To(Succeed())
+
+		b, err := os.ReadFile(layer.SBOMPath(libcnb.SPDXJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		var doc tomcat.SPDXDocument
+		Expect(json.Unmarshal(b, &doc)).To(Succeed())
+		Expect(doc.SPDXVersion).To(Equal("SPDX-2.3"))
+		Expect(doc.CreationInfo.Created).To(Equal("1970-01-01T00:00:00Z"))
+		Expect(doc.Packages).To(HaveLen(1))
+		Expect(doc.Packages[0].SPDXID).To(Equal("SPDXRef-Package-tomcat-logging-support"))
+		Expect(doc.Packages[0].LicenseDeclared).To(Equal("Apache-2.0"))
+		Expect(doc.Packages[0].ExternalRefs).To(Equal([]tomcat.SPDXExternalRef{
+			{ReferenceCategory: "SECURITY", ReferenceType: "cpe23Type", ReferenceLocator: "cpe:2.3:a:cloudfoundry:tomcat-logging-support:3.4.0:*:*:*:*:*:*:*"},
+			{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:generic/tomcat-logging-support@3.4.0"},
+		}))
+		Expect(doc.Relationships).To(Equal([]tomcat.SPDXRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Package-tomcat-logging-support"},
+		}))
+	})
+
+	it("writes Syft", func() {
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(d.WriteTo(layer, libcnb.SyftJSON)).To(Succeed())
+		Expect(os.ReadFile(layer.SBOMPath(libcnb.SyftJSON))).To(ContainSubstring("pkg:generic/tomcat-logging-support@3.4.0"))
+	})
+}
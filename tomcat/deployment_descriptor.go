@@ -0,0 +1,276 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+const (
+	NamespaceJ2EE        = "http://java.sun.com/xml/ns/j2ee"
+	NamespaceJavaEE      = "http://java.sun.com/xml/ns/javaee"
+	NamespaceJCPJavaEE   = "http://xmlns.jcp.org/xml/ns/javaee"
+	NamespaceJakartaEE   = "https://jakarta.ee/xml/ns/jakartaee"
+	defaultServletSpec   = "2.3"
+	firstJakartaTomcat   = "10.0.0"
+	firstJakartaServlets = "5.0"
+)
+
+// servletTomcatVersions maps each servlet specification version to the first Tomcat line that implements it.
+var servletTomcatVersions = []struct {
+	Servlet string
+	Tomcat  string
+}{
+	{"2.4", "5.5"},
+	{"2.5", "6.0"},
+	{"3.0", "7.0"},
+	{"3.1", "8.0"},
+	{"4.0", "9.0"},
+	{"5.0", "10.0"},
+	{"6.0", "10.1"},
+	{"6.1", "11.0"},
+}
+
+// DeploymentDescriptor is the servlet specification declared by a web.xml or web-fragment.xml.
+type DeploymentDescriptor struct {
+	Namespace string
+	Path      string
+	Version   string
+}
+
+// Jakarta returns whether the descriptor uses the jakarta.* namespace introduced in Servlet 5.0.
+func (d DeploymentDescriptor) Jakarta() bool {
+	if d.Namespace == NamespaceJakartaEE {
+		return true
+	}
+
+	v, err := semver.NewVersion(d.Version)
+	return err == nil && !v.LessThan(semver.MustParse(firstJakartaServlets))
+}
+
+// NewDeploymentDescriptor reads the root element of a deployment descriptor.  When the version attribute is missing,
+// as in DTD based descriptors, it is derived from the namespace.
+func NewDeploymentDescriptor(path string, in io.Reader) (DeploymentDescriptor, error) {
+	d := DeploymentDescriptor{Path: path}
+
+	decoder := xml.NewDecoder(in)
+	for {
+		t, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return DeploymentDescriptor{}, fmt.Errorf("no root element in %s", path)
+		} else if err != nil {
+			return DeploymentDescriptor{}, fmt.Errorf("unable to decode %s\n%w", path, err)
+		}
+
+		s, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		d.Namespace = s.Name.Space
+		for _, a := range s.Attr {
+			if a.Name.Local == "version" {
+				d.Version = a.Value
+			}
+		}
+		break
+	}
+
+	if d.Version == "" {
+		switch d.Namespace {
+		case NamespaceJ2EE:
+			d.Version = "2.4"
+		case NamespaceJavaEE:
+			d.Version = "2.5"
+		case NamespaceJCPJavaEE:
+			d.Version = "3.1"
+		case NamespaceJakartaEE:
+			d.Version = firstJakartaServlets
+		default:
+			d.Version = defaultServletSpec
+		}
+	}
+
+	return d, nil
+}
+
+// ReadDeploymentDescriptors reads WEB-INF/web.xml, WEB-INF/classes/META-INF/web-fragment.xml and the
+// META-INF/web-fragment.xml of each JAR in WEB-INF/lib of a webapp.  JARs that cannot be opened are skipped with a
+// warning.
+func ReadDeploymentDescriptors(webapp string, logger bard.Logger) ([]DeploymentDescriptor, error) {
+	var descriptors []DeploymentDescriptor
+
+	for _, file := range []string{
+		filepath.Join(webapp, "WEB-INF", "web.xml"),
+		filepath.Join(webapp, "WEB-INF", "classes", "META-INF", "web-fragment.xml"),
+	} {
+		in, err := os.Open(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to open %s\n%w", file, err)
+		}
+
+		d, err := NewDeploymentDescriptor(file, in)
+		_ = in.Close()
+		if err != nil {
+			return nil, err
+		}
+		descriptors = append(descriptors, d)
+	}
+
+	jars, err := filepath.Glob(filepath.Join(webapp, "WEB-INF", "lib", "*.jar"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list JARs in %s\n%w", webapp, err)
+	}
+	sort.Strings(jars)
+
+	for _, jar := range jars {
+		d, ok, err := readWebFragment(jar, logger)
+		if err != nil {
+			return nil, err
+		} else if ok {
+			descriptors = append(descriptors, d)
+		}
+	}
+
+	return descriptors, nil
+}
+
+func readWebFragment(jar string, logger bard.Logger) (DeploymentDescriptor, bool, error) {
+	z, err := zip.OpenReader(jar)
+	if err != nil {
+		logger.Bodyf("WARNING: skipping %s, unable to open as JAR: %s", jar, err)
+		return DeploymentDescriptor{}, false, nil
+	}
+	defer z.Close()
+
+	for _, f := range z.File {
+		if f.Name != "META-INF/web-fragment.xml" {
+			continue
+		}
+
+		in, err := f.Open()
+		if err != nil {
+			return DeploymentDescriptor{}, false, fmt.Errorf("unable to open %s in %s\n%w", f.Name, jar, err)
+		}
+		defer in.Close()
+
+		d, err := NewDeploymentDescriptor(fmt.Sprintf("%s!/%s", jar, f.Name), in)
+		if err != nil {
+			return DeploymentDescriptor{}, false, err
+		}
+		return d, true, nil
+	}
+
+	return DeploymentDescriptor{}, false, nil
+}
+
+// TomcatVersionSelector chooses the oldest Tomcat line among the buildpack's dependencies that implements the servlet
+// specification declared by a set of deployment descriptors.
+type TomcatVersionSelector struct {
+	Dependencies []libpak.BuildpackDependency
+	Logger       bard.Logger
+}
+
+// Select returns a version constraint such as 10.1.* for the chosen Tomcat line.  It returns false when there are no
+// descriptors to base a choice on or no Tomcat line is compatible with them.
+func (t TomcatVersionSelector) Select(descriptors []DeploymentDescriptor) (string, bool, error) {
+	if len(descriptors) == 0 {
+		return "", false, nil
+	}
+
+	var (
+		jakarta, javax bool
+		spec           *semver.Version
+	)
+	for _, d := range descriptors {
+		v, err := semver.NewVersion(d.Version)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid servlet version %q in %s\n%w", d.Version, d.Path, err)
+		}
+
+		t.Logger.Bodyf("%s declares Servlet %s", d.Path, d.Version)
+		if spec == nil || v.GreaterThan(spec) {
+			spec = v
+		}
+		if d.Jakarta() {
+			jakarta = true
+		} else {
+			javax = true
+		}
+	}
+
+	if jakarta && javax {
+		t.Logger.Body("WARNING: both javax.* and jakarta.* deployment descriptors were found, choosing a jakarta.* Tomcat")
+	}
+
+	minimum := semver.MustParse(firstJakartaTomcat)
+	if !jakarta {
+		minimum = semver.MustParse("0.0.0")
+	}
+	required := semver.MustParse(servletTomcatVersions[len(servletTomcatVersions)-1].Tomcat)
+	for _, s := range servletTomcatVersions {
+		if !semver.MustParse(s.Servlet).LessThan(spec) {
+			required = semver.MustParse(s.Tomcat)
+			break
+		}
+	}
+	if required.GreaterThan(minimum) {
+		minimum = required
+	}
+
+	var candidates []*semver.Version
+	for _, d := range t.Dependencies {
+		if d.ID != "tomcat" {
+			continue
+		}
+
+		v, err := semver.NewVersion(d.Version)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to parse version %s of %s\n%w", d.Version, d.ID, err)
+		}
+
+		if v.LessThan(minimum) || (!jakarta && !v.LessThan(semver.MustParse(firstJakartaTomcat))) {
+			continue
+		}
+		candidates = append(candidates, v)
+	}
+
+	if len(candidates) == 0 {
+		namespace := "javax.*"
+		if jakarta {
+			namespace = "jakarta.*"
+		}
+		t.Logger.Bodyf("WARNING: no Tomcat version in the buildpack supports Servlet %s with the %s namespace, using the default version", spec.Original(), namespace)
+		return "", false, nil
+	}
+
+	sort.Sort(semver.Collection(candidates))
+	return fmt.Sprintf("%d.%d.*", candidates[0].Major(), candidates[0].Minor()), true, nil
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testDeploymentDescriptor(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	context("NewDeploymentDescriptor", func() {
+		it("reads version attribute", func() {
+			d, err := tomcat.NewDeploymentDescriptor("web.xml", strings.NewReader(
+				`<?xml version="1.0"?><web-app xmlns="http://xmlns.jcp.org/xml/ns/javaee" version="4.0"/>`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Namespace).To(Equal(tomcat.NamespaceJCPJavaEE))
+			Expect(d.Version).To(Equal("4.0"))
+			Expect(d.Jakarta()).To(BeFalse())
+		})
+
+		it("derives version from namespace", func() {
+			d, err := tomcat.NewDeploymentDescriptor("web.xml", strings.NewReader(`<web-app xmlns="https://jakarta.ee/xml/ns/jakartaee"/>`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Version).To(Equal("5.0"))
+			Expect(d.Jakarta()).To(BeTrue())
+		})
+
+		it("defaults version of DTD based descriptors", func() {
+			d, err := tomcat.NewDeploymentDescriptor("web.xml", strings.NewReader(
+				`<!DOCTYPE web-app PUBLIC "-//Sun Microsystems, Inc.//DTD Web Application 2.3//EN" "http://java.sun.com/dtd/web-app_2_3.dtd"><web-app/>`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d.Version).To(Equal("2.3"))
+		})
+
+		it("fails without root element", func() {
+			_, err := tomcat.NewDeploymentDescriptor("web.xml", strings.NewReader(""))
+			Expect(err).To(MatchError("no root element in web.xml"))
+		})
+	})
+
+	context("ReadDeploymentDescriptors", func() {
+		var path string
+
+		it.Before(func() {
+			var err error
+			path, err = os.MkdirTemp("", "deployment-descriptor")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.MkdirAll(filepath.Join(path, "WEB-INF", "lib"), 0755)).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.RemoveAll(path)).To(Succeed())
+		})
+
+		it("reads web.xml and web fragments", func() {
+			Expect(os.WriteFile(filepath.Join(path, "WEB-INF", "web.xml"),
+				[]byte(`<web-app xmlns="http://xmlns.jcp.org/xml/ns/javaee" version="3.1"/>`), 0644)).To(Succeed())
+
+			out, err := os.Create(filepath.Join(path, "WEB-INF", "lib", "fragment.jar"))
+			Expect(err).NotTo(HaveOccurred())
+			z := zip.NewWriter(out)
+			w, err := z.Create("META-INF/web-fragment.xml")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte(`<web-fragment xmlns="https://jakarta.ee/xml/ns/jakartaee" version="6.0"/>`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(z.Close()).To(Succeed())
+			Expect(out.Close()).To(Succeed())
+
+			d, err := tomcat.ReadDeploymentDescriptors(path, bard.NewLogger(io.Discard))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(d).To(Equal([]tomcat.DeploymentDescriptor{
+				{
+					Namespace: tomcat.NamespaceJCPJavaEE,
+					Path:      filepath.Join(path, "WEB-INF", "web.xml"),
+					Version:   "3.1",
+				},
+				{
+					Namespace: tomcat.NamespaceJakartaEE,
+					Path:      filepath.Join(path, "WEB-INF", "lib", "fragment.jar") + "!/META-INF/web-fragment.xml",
+					Version:   "6.0",
+				},
+			}))
+		})
+
+		it("skips JARs that are not zip files", func() {
+			Expect(os.WriteFile(filepath.Join(path, "WEB-INF", "lib", "broken.jar"), []byte("not a zip"), 0644)).To(Succeed())
+
+			b := &bytes.Buffer{}
+			Expect(tomcat.ReadDeploymentDescriptors(path, bard.NewLogger(b))).To(BeEmpty())
+			Expect(b.String()).To(ContainSubstring("WARNING: skipping " + filepath.Join(path, "WEB-INF", "lib", "broken.jar")))
+		})
+
+		it("returns nothing without descriptors", func() {
+			Expect(tomcat.ReadDeploymentDescriptors(path, bard.NewLogger(io.Discard))).To(BeEmpty())
+		})
+	})
+
+	context("TomcatVersionSelector", func() {
+		var selector tomcat.TomcatVersionSelector
+
+		it.Before(func() {
+			selector.Dependencies = []libpak.BuildpackDependency{
+				{ID: "tomcat", Version: "9.0.1"},
+				{ID: "tomcat", Version: "9.0.2"},
+				{ID: "tomcat", Version: "10.1.1"},
+				{ID: "tomcat", Version: "11.0.1"},
+				{ID: "tomcat-logging-support", Version: "3.4.0"},
+			}
+		})
+
+		it("does not select without descriptors", func() {
+			_, found, err := selector.Select(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		it("selects Tomcat 9 for javax.* descriptors", func() {
+			v, found, err := selector.Select([]tomcat.DeploymentDescriptor{{Namespace: tomcat.NamespaceJavaEE, Version: "3.0"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(v).To(Equal("9.0.*"))
+		})
+
+		it("selects the oldest jakarta.* Tomcat", func() {
+			v, found, err := selector.Select([]tomcat.DeploymentDescriptor{{Namespace: tomcat.NamespaceJakartaEE, Version: "5.0"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(v).To(Equal("10.1.*"))
+		})
+
+		it("selects the Tomcat for the highest servlet version", func() {
+			v, found, err := selector.Select([]tomcat.DeploymentDescriptor{
+				{Namespace: tomcat.NamespaceJakartaEE, Version: "5.0"},
+				{Namespace: tomcat.NamespaceJakartaEE, Version: "6.1"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(v).To(Equal("11.0.*"))
+		})
+
+		it("does not select when no Tomcat is compatible", func() {
+			selector.Dependencies = []libpak.BuildpackDependency{{ID: "tomcat", Version: "10.1.1"}}
+
+			_, found, err := selector.Select([]tomcat.DeploymentDescriptor{{Namespace: tomcat.NamespaceJCPJavaEE, Version: "4.0"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+}
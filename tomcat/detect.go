@@ -18,12 +18,10 @@ package tomcat
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"strconv"
 
 	"github.com/buildpacks/libcnb"
-	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
-	"github.com/paketo-buildpacks/libjvm"
 	"github.com/paketo-buildpacks/libpak"
 	"github.com/paketo-buildpacks/libpak/bard"
 )
@@ -53,17 +51,37 @@ func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error
 		return libcnb.DetectResult{Pass: false}, nil
 	}
 
-	warFilesExist, _ := util.ContainsWarFiles(context.Application.Path)
-	if !warFilesExist {
-		m, err := libjvm.NewManifest(context.Application.Path)
-		if err != nil {
-			return libcnb.DetectResult{}, fmt.Errorf("unable to read manifest\n%w", err)
-		}
+	layout, err := NewApplicationLayout(context.Application.Path)
+	if err != nil {
+		return libcnb.DetectResult{}, fmt.Errorf("unable to inspect application\n%w", err)
+	}
+	layout.Report(d.Logger)
 
-		if _, ok := m.Get("Main-Class"); ok {
-			d.Logger.Info("SKIPPED: Manifest attribute 'Main-Class' was found")
+	deployExecutableWar := cr.ResolveBool("BP_TOMCAT_DEPLOY_EXECUTABLE_WAR")
+
+	switch layout.Kind {
+	case ApplicationMainClass:
+		d.Logger.Info("SKIPPED: Manifest attribute 'Main-Class' was found")
+		return libcnb.DetectResult{Pass: false}, nil
+	case ApplicationExecutableWar:
+		if !deployExecutableWar {
+			d.Logger.Info("SKIPPED: Manifest attribute 'Main-Class' was found, set BP_TOMCAT_DEPLOY_EXECUTABLE_WAR to deploy it into Tomcat")
 			return libcnb.DetectResult{Pass: false}, nil
 		}
+	case ApplicationWebFragment:
+		d.Logger.Info("SKIPPED: a web fragment JAR cannot be deployed on its own")
+		return libcnb.DetectResult{Pass: false}, nil
+	}
+
+	jre := map[string]interface{}{"launch": true}
+	if minimum, err := d.MinimumJavaVersion(context, cr, layout); err != nil {
+		return libcnb.DetectResult{}, fmt.Errorf("unable to determine minimum Java version\n%w", err)
+	} else if minimum > 0 {
+		jre["minimum-version"] = strconv.Itoa(minimum)
+	}
+	if cr.ResolveBool("BP_TOMCAT_APPCDS_ENABLED") {
+		// the AppCDS training run starts Tomcat at build time
+		jre["build"] = true
 	}
 
 	result := libcnb.DetectResult{
@@ -76,7 +94,7 @@ func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error
 				},
 				Requires: []libcnb.BuildPlanRequire{
 					{Name: PlanEntrySyft},
-					{Name: PlanEntryJRE, Metadata: map[string]interface{}{"launch": true}},
+					{Name: PlanEntryJRE, Metadata: jre},
 					{Name: PlanEntryJVMApplicationPackage},
 					{Name: PlanEntryJVMApplication},
 					{Name: PlanEntryJavaApplicationServer},
@@ -85,10 +103,7 @@ func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error
 		},
 	}
 
-	file := filepath.Join(context.Application.Path, "WEB-INF")
-	if _, err := os.Stat(file); err != nil && !os.IsNotExist(err) {
-		return libcnb.DetectResult{}, fmt.Errorf("unable to stat file %s\n%w", file, err)
-	} else if os.IsNotExist(err) && !warFilesExist {
+	if !layout.Kind.Deployable() && layout.Kind != ApplicationExecutableWar {
 		d.Logger.Info("PASSED: a WEB-INF directory was not found, this is normal when building from source")
 		return result, nil
 	}
@@ -96,3 +111,29 @@ func (d Detect) Detect(context libcnb.DetectContext) (libcnb.DetectResult, error
 	result.Plans[0].Provides = append(result.Plans[0].Provides, libcnb.BuildPlanProvide{Name: PlanEntryJVMApplicationPackage})
 	return result, nil
 }
+
+// MinimumJavaVersion returns the minimum Java version required by the Tomcat that will be contributed, or zero if it
+// cannot be determined.
+func (d Detect) MinimumJavaVersion(context libcnb.DetectContext, cr libpak.ConfigurationResolver, layout ApplicationLayout) (int, error) {
+	md, err := libpak.NewBuildpackMetadata(context.Buildpack.Metadata)
+	if err != nil {
+		return 0, fmt.Errorf("unable to unmarshal buildpack metadata\n%w", err)
+	}
+
+	v, ok := cr.Resolve("BP_TOMCAT_VERSION")
+	if !ok {
+		if s, found, err := SelectTomcatVersion(context.Buildpack, layout, bard.NewLogger(io.Discard)); err != nil {
+			return 0, err
+		} else if found {
+			v = s
+		}
+	}
+
+	dr := libpak.DependencyResolver{Dependencies: md.Dependencies, StackID: context.StackID}
+	tomcat, err := dr.Resolve("tomcat", v)
+	if err != nil {
+		return 0, nil
+	}
+
+	return MinimumJavaVersion(tomcat)
+}
@@ -57,6 +57,121 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 		Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{Pass: false}))
 	})
 
+	it("fails with Spring Boot WAR", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "WEB-INF", "lib-provided"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "META-INF", "MANIFEST.MF"),
+			[]byte(`Main-Class: org.springframework.boot.loader.WarLauncher`), 0644)).To(Succeed())
+
+		Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{Pass: false}))
+	})
+
+	context("$BP_TOMCAT_DEPLOY_EXECUTABLE_WAR", func() {
+		it.Before(func() {
+			Expect(os.Setenv("BP_TOMCAT_DEPLOY_EXECUTABLE_WAR", "true")).To(Succeed())
+		})
+
+		it.After(func() {
+			Expect(os.Unsetenv("BP_TOMCAT_DEPLOY_EXECUTABLE_WAR")).To(Succeed())
+		})
+
+		it("passes with Spring Boot WAR", func() {
+			Expect(os.MkdirAll(filepath.Join(path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "META-INF", "MANIFEST.MF"),
+				[]byte(`Main-Class: org.springframework.boot.loader.WarLauncher`), 0644)).To(Succeed())
+
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Pass).To(BeTrue())
+			Expect(result.Plans[0].Provides).To(ContainElement(libcnb.BuildPlanProvide{Name: "jvm-application-package"}))
+		})
+
+		it("fails with Main-Class", func() {
+			Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "META-INF", "MANIFEST.MF"), []byte(`Main-Class: test-main-class`), 0644)).To(Succeed())
+
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{Pass: false}))
+		})
+	})
+
+	it("fails with web fragment JAR", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "META-INF", "web-fragment.xml"), []byte(`<web-fragment/>`), 0644)).To(Succeed())
+
+		Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{Pass: false}))
+	})
+
+	context("nested webapps found", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(path, "api", "WEB-INF"), 0755)).To(Succeed())
+		})
+
+		it("requires and provides jvm-application-artifact", func() {
+			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{
+				Pass: true,
+				Plans: []libcnb.BuildPlan{
+					{
+						Provides: []libcnb.BuildPlanProvide{
+							{Name: "jvm-application"},
+							{Name: "java-app-server"},
+							{Name: "jvm-application-package"},
+						},
+						Requires: []libcnb.BuildPlanRequire{
+							{Name: "syft"},
+							{Name: "jre", Metadata: map[string]interface{}{"launch": true}},
+							{Name: "jvm-application-package"},
+							{Name: "jvm-application"},
+							{Name: "java-app-server"},
+						},
+					},
+				},
+			}))
+		})
+	})
+
+	context("Tomcat requires a newer Java", func() {
+		it.Before(func() {
+			ctx.Buildpack.Metadata = map[string]interface{}{
+				"dependencies": []map[string]interface{}{
+					{"id": "tomcat", "version": "11.0.1", "stacks": []interface{}{"test-stack-id"}},
+				},
+			}
+			ctx.StackID = "test-stack-id"
+			Expect(os.MkdirAll(filepath.Join(path, "WEB-INF"), 0755)).To(Succeed())
+		})
+
+		it.After(func() {
+			ctx.Buildpack.Metadata = nil
+			ctx.StackID = ""
+		})
+
+		it("requires minimum Java version", func() {
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(ContainElement(libcnb.BuildPlanRequire{
+				Name:     "jre",
+				Metadata: map[string]interface{}{"launch": true, "minimum-version": "17"},
+			}))
+		})
+	})
+
+	context("$BP_TOMCAT_APPCDS_ENABLED", func() {
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(path, "WEB-INF"), 0755)).To(Succeed())
+			t.Setenv("BP_TOMCAT_APPCDS_ENABLED", "true")
+		})
+
+		it("requires JRE at build time", func() {
+			result, err := detect.Detect(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Plans[0].Requires).To(ContainElement(libcnb.BuildPlanRequire{
+				Name:     "jre",
+				Metadata: map[string]interface{}{"launch": true, "build": true},
+			}))
+		})
+	})
+
 	context("WEB-INF not found", func() {
 		it("requires jvm-application-artifact", func() {
 			Expect(detect.Detect(ctx)).To(Equal(libcnb.DetectResult{
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/libjvm"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// EmbeddedContainerJARs are the patterns of JARs in WEB-INF/lib that embed a servlet container and conflict with the
+// Tomcat contributed by this buildpack.  Other Jetty and Undertow artifacts, such as jetty-client, are ordinary
+// application dependencies and are kept.
+var EmbeddedContainerJARs = []string{
+	"tomcat-embed-*.jar",
+	"jetty-server-*.jar",
+	"jetty-servlet-*.jar",
+	"jetty-webapp-*.jar",
+	"undertow-core-*.jar",
+	"undertow-servlet-*.jar",
+}
+
+// ExecutableWar prepares an executable WAR, such as a Spring Boot WAR, to be deployed into Tomcat.  It removes the
+// launcher classes and the JARs of the embedded container so that only the servlet application remains.
+type ExecutableWar struct {
+	Logger bard.Logger
+	Path   string
+}
+
+// Strip removes WEB-INF/lib-provided, embedded container JARs in WEB-INF/lib and the Spring Boot loader classes at the
+// root of the webapp.  Webapps without a Main-Class are left unchanged.
+func (e ExecutableWar) Strip() error {
+	m, err := libjvm.NewManifest(e.Path)
+	if err != nil {
+		return fmt.Errorf("unable to read manifest\n%w", err)
+	}
+
+	if _, ok := m.Get("Main-Class"); !ok {
+		return nil
+	}
+
+	remove := []string{
+		filepath.Join(e.Path, "WEB-INF", "lib-provided"),
+		filepath.Join(e.Path, "org", "springframework", "boot", "loader"),
+	}
+
+	for _, pattern := range EmbeddedContainerJARs {
+		jars, err := filepath.Glob(filepath.Join(e.Path, "WEB-INF", "lib", pattern))
+		if err != nil {
+			return fmt.Errorf("unable to find embedded container JARs in %s\n%w", e.Path, err)
+		}
+		remove = append(remove, jars...)
+	}
+
+	for _, r := range remove {
+		if _, err := os.Stat(r); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("unable to stat file %s\n%w", r, err)
+		}
+
+		e.Logger.Bodyf("Removing %s", r)
+		if err := os.RemoveAll(r); err != nil {
+			return fmt.Errorf("unable to remove %s\n%w", r, err)
+		}
+	}
+
+	return nil
+}
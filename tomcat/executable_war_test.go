@@ -0,0 +1,93 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testExecutableWar(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		path, err = os.MkdirTemp("", "executable-war")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(path, "WEB-INF", "lib-provided"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "WEB-INF", "lib-provided", "tomcat-embed-core-10.1.0.jar"), []byte{}, 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(path, "WEB-INF", "lib"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "WEB-INF", "lib", "tomcat-embed-websocket-10.1.0.jar"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "WEB-INF", "lib", "spring-web-6.0.0.jar"), []byte{}, 0644)).To(Succeed())
+		for _, jar := range []string{"jetty-client-11.0.0.jar", "jetty-server-11.0.0.jar", "jetty-servlet-11.0.0.jar",
+			"jetty-util-11.0.0.jar", "undertow-core-2.3.0.jar", "undertow-servlet-2.3.0.jar"} {
+			Expect(os.WriteFile(filepath.Join(path, "WEB-INF", "lib", jar), []byte{}, 0644)).To(Succeed())
+		}
+		Expect(os.MkdirAll(filepath.Join(path, "org", "springframework", "boot", "loader"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "org", "springframework", "boot", "loader", "WarLauncher.class"), []byte{}, 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("strips executable WAR", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "META-INF", "MANIFEST.MF"),
+			[]byte("Main-Class: org.springframework.boot.loader.WarLauncher"), 0644)).To(Succeed())
+
+		Expect(tomcat.ExecutableWar{Path: path}.Strip()).To(Succeed())
+
+		Expect(filepath.Join(path, "WEB-INF", "lib-provided")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "tomcat-embed-websocket-10.1.0.jar")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "spring-web-6.0.0.jar")).To(BeARegularFile())
+		Expect(filepath.Join(path, "org", "springframework", "boot", "loader")).NotTo(BeAnExistingFile())
+	})
+
+	it("keeps Jetty and Undertow artifacts that are application dependencies", func() {
+		Expect(os.MkdirAll(filepath.Join(path, "META-INF"), 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "META-INF", "MANIFEST.MF"),
+			[]byte("Main-Class: org.springframework.boot.loader.WarLauncher"), 0644)).To(Succeed())
+
+		Expect(tomcat.ExecutableWar{Path: path}.Strip()).To(Succeed())
+
+		Expect(filepath.Join(path, "WEB-INF", "lib", "jetty-client-11.0.0.jar")).To(BeARegularFile())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "jetty-util-11.0.0.jar")).To(BeARegularFile())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "jetty-server-11.0.0.jar")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "jetty-servlet-11.0.0.jar")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "undertow-core-2.3.0.jar")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "undertow-servlet-2.3.0.jar")).NotTo(BeAnExistingFile())
+	})
+
+	it("does not strip webapp without Main-Class", func() {
+		Expect(tomcat.ExecutableWar{Path: path}.Strip()).To(Succeed())
+
+		Expect(filepath.Join(path, "WEB-INF", "lib-provided")).To(BeADirectory())
+		Expect(filepath.Join(path, "WEB-INF", "lib", "tomcat-embed-websocket-10.1.0.jar")).To(BeARegularFile())
+	})
+}
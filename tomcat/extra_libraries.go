@@ -0,0 +1,140 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// ExtraLibraryPrefix is the prefix of the ids of buildpack.toml dependencies that are installed in $CATALINA_BASE/lib.
+const ExtraLibraryPrefix = "tomcat-extra-lib-"
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ParseExtraLibraries parses $BP_TOMCAT_EXTRA_LIBS, a comma-separated list of <uri>=<sha256> pairs, into dependencies.
+func ParseExtraLibraries(s string, stackID string) ([]libpak.BuildpackDependency, error) {
+	var dependencies []libpak.BuildpackDependency
+
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e == "" {
+			continue
+		}
+
+		i := strings.LastIndex(e, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid extra library %q, must be <uri>=<sha256>", e)
+		}
+
+		uri, sha256 := strings.TrimSpace(e[:i]), strings.ToLower(strings.TrimSpace(e[i+1:]))
+		if uri == "" {
+			return nil, fmt.Errorf("invalid extra library %q, must be <uri>=<sha256>", e)
+		}
+		if !sha256Pattern.MatchString(sha256) {
+			return nil, fmt.Errorf("invalid SHA256 %q of extra library %s", sha256, uri)
+		}
+
+		name := fileName(uri)
+		dependencies = append(dependencies, libpak.BuildpackDependency{
+			ID:      ExtraLibraryPrefix + strings.TrimSuffix(name, ".jar"),
+			Name:    name,
+			Version: sha256[:12],
+			URI:     uri,
+			SHA256:  sha256,
+			Stacks:  []string{stackID},
+		})
+	}
+
+	if err := checkExtraLibraries(dependencies); err != nil {
+		return nil, err
+	}
+
+	return dependencies, nil
+}
+
+// NewExtraLibraries returns the extra libraries to install in $CATALINA_BASE/lib: the buildpack.toml dependencies whose
+// ids start with ExtraLibraryPrefix and that support the stack, followed by the libraries in $BP_TOMCAT_EXTRA_LIBS.
+func NewExtraLibraries(buildpack libcnb.Buildpack, resolver libpak.DependencyResolver, configurationResolver libpak.ConfigurationResolver, stackID string) ([]libpak.BuildpackDependency, error) {
+	md, err := libpak.NewBuildpackMetadata(buildpack.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal buildpack metadata\n%w", err)
+	}
+
+	ids := map[string]bool{}
+	for _, d := range md.Dependencies {
+		if strings.HasPrefix(d.ID, ExtraLibraryPrefix) {
+			ids[d.ID] = true
+		}
+	}
+
+	var sorted []string
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	var dependencies []libpak.BuildpackDependency
+	for _, id := range sorted {
+		d, err := resolver.Resolve(id, "")
+		if libpak.IsNoValidDependencies(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to find dependency\n%w", err)
+		}
+		dependencies = append(dependencies, d)
+	}
+
+	if s, ok := configurationResolver.Resolve("BP_TOMCAT_EXTRA_LIBS"); ok {
+		d, err := ParseExtraLibraries(s, stackID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse BP_TOMCAT_EXTRA_LIBS\n%w", err)
+		}
+		dependencies = append(dependencies, d...)
+	}
+
+	if err := checkExtraLibraries(dependencies); err != nil {
+		return nil, err
+	}
+
+	return dependencies, nil
+}
+
+// checkExtraLibraries returns an error if two extra libraries have the same id or would be installed as the same file
+// in $CATALINA_BASE/lib.
+func checkExtraLibraries(dependencies []libpak.BuildpackDependency) error {
+	ids, files := map[string]string{}, map[string]string{}
+
+	for _, d := range dependencies {
+		if uri, ok := ids[d.ID]; ok {
+			return fmt.Errorf("extra libraries %s and %s have the same id %s", uri, d.URI, d.ID)
+		}
+		ids[d.ID] = d.URI
+
+		name := fileName(d.URI)
+		if uri, ok := files[name]; ok {
+			return fmt.Errorf("extra libraries %s and %s have the same file name %s", uri, d.URI, name)
+		}
+		files[name] = d.URI
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testExtraLibraries(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		sha256 = "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+	)
+
+	it("parses extra libraries", func() {
+		Expect(tomcat.ParseExtraLibraries(" https://localhost/postgresql.jar="+sha256+", https://localhost/valve.jar?a=b="+sha256, "test-stack-id")).
+			To(Equal([]libpak.BuildpackDependency{
+				{
+					ID:      "tomcat-extra-lib-postgresql",
+					Name:    "postgresql.jar",
+					Version: "a1b2c3d4e5f6",
+					URI:     "https://localhost/postgresql.jar",
+					SHA256:  sha256,
+					Stacks:  []string{"test-stack-id"},
+				},
+				{
+					ID:      "tomcat-extra-lib-valve",
+					Name:    "valve.jar",
+					Version: "a1b2c3d4e5f6",
+					URI:     "https://localhost/valve.jar?a=b",
+					SHA256:  sha256,
+					Stacks:  []string{"test-stack-id"},
+				},
+			}))
+	})
+
+	it("rejects extra libraries without a SHA256", func() {
+		_, err := tomcat.ParseExtraLibraries("https://localhost/postgresql.jar", "test-stack-id")
+		Expect(err).To(MatchError(`invalid extra library "https://localhost/postgresql.jar", must be <uri>=<sha256>`))
+
+		_, err = tomcat.ParseExtraLibraries("https://localhost/postgresql.jar=abc", "test-stack-id")
+		Expect(err).To(MatchError(`invalid SHA256 "abc" of extra library https://localhost/postgresql.jar`))
+	})
+
+	it("rejects extra libraries with the same name", func() {
+		_, err := tomcat.ParseExtraLibraries("https://one/postgresql.jar="+sha256+",https://two/postgresql.jar?v=2="+sha256, "test-stack-id")
+		Expect(err).To(MatchError("extra libraries https://one/postgresql.jar and https://two/postgresql.jar?v=2 have the same id tomcat-extra-lib-postgresql"))
+	})
+
+	it("rejects $BP_TOMCAT_EXTRA_LIBS that conflict with buildpack dependencies", func() {
+		t.Setenv("BP_TOMCAT_EXTRA_LIBS", "https://localhost/postgresql.jar="+sha256)
+
+		buildpack := libcnb.Buildpack{Metadata: map[string]interface{}{
+			"dependencies": []map[string]interface{}{
+				{"id": "tomcat-extra-lib-driver", "version": "42.0.0", "uri": "https://repo/postgresql.jar", "stacks": []interface{}{"test-stack-id"}},
+			},
+		}}
+		md, err := libpak.NewBuildpackMetadata(buildpack.Metadata)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = tomcat.NewExtraLibraries(buildpack, libpak.DependencyResolver{Dependencies: md.Dependencies, StackID: "test-stack-id"}, libpak.ConfigurationResolver{}, "test-stack-id")
+		Expect(err).To(MatchError("extra libraries https://repo/postgresql.jar and https://localhost/postgresql.jar have the same file name postgresql.jar"))
+	})
+
+	it("combines buildpack dependencies and $BP_TOMCAT_EXTRA_LIBS", func() {
+		t.Setenv("BP_TOMCAT_EXTRA_LIBS", "https://localhost/valve.jar="+sha256)
+
+		buildpack := libcnb.Buildpack{Metadata: map[string]interface{}{
+			"dependencies": []map[string]interface{}{
+				{"id": "tomcat", "version": "1.1.1", "stacks": []interface{}{"test-stack-id"}},
+				{"id": "tomcat-extra-lib-postgresql", "version": "42.0.0", "stacks": []interface{}{"test-stack-id"}},
+				{"id": "tomcat-extra-lib-postgresql", "version": "42.1.0", "stacks": []interface{}{"test-stack-id"}},
+				{"id": "tomcat-extra-lib-other-stack", "version": "1.0.0", "stacks": []interface{}{"other-stack-id"}},
+			},
+		}}
+		resolver := libpak.DependencyResolver{StackID: "test-stack-id"}
+		md, err := libpak.NewBuildpackMetadata(buildpack.Metadata)
+		Expect(err).NotTo(HaveOccurred())
+		resolver.Dependencies = md.Dependencies
+
+		dependencies, err := tomcat.NewExtraLibraries(buildpack, resolver, libpak.ConfigurationResolver{}, "test-stack-id")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dependencies).To(HaveLen(2))
+		Expect(dependencies[0].ID).To(Equal("tomcat-extra-lib-postgresql"))
+		Expect(dependencies[0].Version).To(Equal("42.1.0"))
+		Expect(dependencies[1].URI).To(Equal("https://localhost/valve.jar"))
+	})
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+const (
+	HardeningNone   = "none"
+	HardeningStrict = "strict"
+
+	// DefaultSameSiteCookies is the SameSite attribute the strict profile sets on cookies unless configured.  lax keeps
+	// cross-site top-level navigations, such as SSO and OAuth redirects, working.
+	DefaultSameSiteCookies = "lax"
+)
+
+// DefaultWebapps are the webapps shipped in the webapps directory of the Tomcat distribution.
+var DefaultWebapps = []string{"ROOT", "docs", "examples", "host-manager", "manager"}
+
+// AllowedHTTPMethods are the HTTP methods that are not rejected by the strict hardening profile.
+var AllowedHTTPMethods = []string{"DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT"}
+
+// ParseHardening validates a hardening profile.  The empty string is the none profile.
+func ParseHardening(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "":
+		return HardeningNone, nil
+	case HardeningNone, HardeningStrict:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown hardening profile %q, must be %s or %s", s, HardeningNone, HardeningStrict)
+	}
+}
+
+// ParseSameSiteCookies validates a sameSiteCookies value of Tomcat's Rfc6265CookieProcessor.  The empty string is
+// DefaultSameSiteCookies.
+func ParseSameSiteCookies(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "":
+		return DefaultSameSiteCookies, nil
+	case "unset", "none", "lax", "strict":
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown SameSite cookie attribute %q, must be unset, none, lax or strict", s)
+	}
+}
+
+// Hardening applies a hardening profile to the configuration in $CATALINA_BASE/conf.  The strict profile disables
+// X-Powered-By, and pins allowTrace to Tomcat's default of false, on every Connector, sets secure and HttpOnly session
+// cookies and the SameSiteCookies attribute on cookies, adds HttpHeaderSecurityFilter and rejects HTTP methods other
+// than AllowedHTTPMethods.
+type Hardening struct {
+	Logger          bard.Logger
+	Profile         string
+	SameSiteCookies string
+}
+
+func (h Hardening) Configure(catalinaBase string) error {
+	if h.Profile != HardeningStrict {
+		return nil
+	}
+
+	conf := filepath.Join(catalinaBase, "conf")
+
+	if err := h.configureServer(filepath.Join(conf, "server.xml")); err != nil {
+		return err
+	}
+
+	if err := h.configureContext(filepath.Join(conf, "context.xml")); err != nil {
+		return err
+	}
+
+	if err := h.configureWeb(filepath.Join(conf, "web.xml")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h Hardening) configureServer(file string) error {
+	h.Logger.Body("Disabling X-Powered-By on Connectors")
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	connector := util.Element("Connector")
+	if !connector.Match(b) {
+		return fmt.Errorf("unable to find Connector in %s", file)
+	}
+	s := connector.ReplaceAllStringFunc(string(b), func(c string) string {
+		return util.AddAttributes(c, [][2]string{{"allowTrace", "false"}, {"xpoweredBy", "false"}})
+	})
+
+	if err := os.WriteFile(file, []byte(s), 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return nil
+}
+
+func (h Hardening) configureContext(file string) error {
+	sameSite, err := ParseSameSiteCookies(h.SameSiteCookies)
+	if err != nil {
+		return err
+	}
+
+	h.Logger.Bodyf("Setting SameSite=%s on cookies", sameSite)
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	if processor := util.Element("CookieProcessor"); processor.Match(b) {
+		s := processor.ReplaceAllStringFunc(string(b), func(c string) string {
+			return util.AddAttributes(c, [][2]string{{"sameSiteCookies", sameSite}})
+		})
+		if err := os.WriteFile(file, []byte(s), 0644); err != nil {
+			return fmt.Errorf("unable to write file %s\n%w", file, err)
+		}
+		return nil
+	}
+
+	if ok, err := util.InsertBefore(file, "</Context>", fmt.Sprintf(`    <CookieProcessor className='org.apache.tomcat.util.http.Rfc6265CookieProcessor' sameSiteCookies='%s'/>
+`, sameSite)); err != nil {
+		return fmt.Errorf("unable to configure cookies in %s\n%w", file, err)
+	} else if !ok {
+		return fmt.Errorf("unable to find </Context> in %s", file)
+	}
+
+	return nil
+}
+
+func (h Hardening) configureWeb(file string) error {
+	h.Logger.Body("Setting Secure and HttpOnly on session cookies")
+
+	cookieConfig := `
+        <cookie-config>
+            <http-only>true</http-only>
+            <secure>true</secure>
+        </cookie-config>
+        <tracking-mode>COOKIE</tracking-mode>
+    `
+	if ok, err := util.InsertBefore(file, "</session-config>", cookieConfig); err != nil {
+		return fmt.Errorf("unable to configure session cookies in %s\n%w", file, err)
+	} else if !ok {
+		if ok, err := util.InsertBefore(file, "</web-app>", fmt.Sprintf("    <session-config>%s</session-config>\n", cookieConfig)); err != nil {
+			return fmt.Errorf("unable to configure session cookies in %s\n%w", file, err)
+		} else if !ok {
+			return fmt.Errorf("unable to find </web-app> in %s", file)
+		}
+	}
+
+	h.Logger.Body("Adding HttpHeaderSecurityFilter")
+	h.Logger.Bodyf("Allowing HTTP methods %s", strings.Join(AllowedHTTPMethods, ", "))
+
+	var b strings.Builder
+	b.WriteString(`
+    <filter>
+        <filter-name>hardeningHttpHeaderSecurity</filter-name>
+        <filter-class>org.apache.catalina.filters.HttpHeaderSecurityFilter</filter-class>
+        <async-supported>true</async-supported>
+    </filter>
+    <filter-mapping>
+        <filter-name>hardeningHttpHeaderSecurity</filter-name>
+        <url-pattern>/*</url-pattern>
+        <dispatcher>REQUEST</dispatcher>
+    </filter-mapping>
+    <security-constraint>
+        <web-resource-collection>
+            <web-resource-name>Disallowed HTTP methods</web-resource-name>
+            <url-pattern>/*</url-pattern>`)
+	for _, m := range AllowedHTTPMethods {
+		_, _ = fmt.Fprintf(&b, `
+            <http-method-omission>%s</http-method-omission>`, m)
+	}
+	b.WriteString(`
+        </web-resource-collection>
+        <auth-constraint/>
+    </security-constraint>
+`)
+
+	if ok, err := util.InsertBefore(file, "</web-app>", b.String()); err != nil {
+		return fmt.Errorf("unable to configure security constraints in %s\n%w", file, err)
+	} else if !ok {
+		return fmt.Errorf("unable to find </web-app> in %s", file)
+	}
+
+	return nil
+}
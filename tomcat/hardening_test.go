@@ -0,0 +1,151 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testHardening(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		path, err = os.MkdirTemp("", "hardening")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(path, "conf"), 0755)).To(Succeed())
+		for _, f := range []string{"context.xml", "server.xml", "web.xml"} {
+			b, err := os.ReadFile(filepath.Join("..", "resources", f))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(path, "conf", f), b, 0644)).To(Succeed())
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("parses hardening profiles", func() {
+		Expect(tomcat.ParseHardening("")).To(Equal(tomcat.HardeningNone))
+		Expect(tomcat.ParseHardening("Strict")).To(Equal(tomcat.HardeningStrict))
+
+		_, err := tomcat.ParseHardening("paranoid")
+		Expect(err).To(MatchError(`unknown hardening profile "paranoid", must be none or strict`))
+	})
+
+	it("parses SameSite cookie attributes", func() {
+		Expect(tomcat.ParseSameSiteCookies("")).To(Equal("lax"))
+		Expect(tomcat.ParseSameSiteCookies("Strict")).To(Equal("strict"))
+
+		_, err := tomcat.ParseSameSiteCookies("always")
+		Expect(err).To(MatchError(`unknown SameSite cookie attribute "always", must be unset, none, lax or strict`))
+	})
+
+	it("does not change configuration without a profile", func() {
+		Expect(tomcat.Hardening{Profile: tomcat.HardeningNone}.Configure(path)).To(Succeed())
+
+		expected, err := os.ReadFile(filepath.Join("..", "resources", "server.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.ReadFile(filepath.Join(path, "conf", "server.xml"))).To(Equal(expected))
+	})
+
+	it("applies the strict profile", func() {
+		Expect(tomcat.Hardening{Profile: tomcat.HardeningStrict}.Configure(path)).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(path, "conf", "server.xml"))).
+			To(ContainSubstring("<Connector allowTrace='false' xpoweredBy='false' port='8080'"))
+		Expect(os.ReadFile(filepath.Join(path, "conf", "context.xml"))).
+			To(ContainSubstring("<CookieProcessor className='org.apache.tomcat.util.http.Rfc6265CookieProcessor' sameSiteCookies='lax'/>\n</Context>"))
+
+		b, err := os.ReadFile(filepath.Join(path, "conf", "web.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring("<secure>true</secure>"))
+		Expect(string(b)).To(ContainSubstring("<tracking-mode>COOKIE</tracking-mode>"))
+		Expect(string(b)).To(ContainSubstring("<filter-class>org.apache.catalina.filters.HttpHeaderSecurityFilter</filter-class>"))
+		Expect(string(b)).To(ContainSubstring("<http-method-omission>GET</http-method-omission>"))
+		Expect(string(b)).NotTo(ContainSubstring("<http-method-omission>TRACE</http-method-omission>"))
+
+		for _, f := range []string{"context.xml", "server.xml", "web.xml"} {
+			in, err := os.Open(filepath.Join(path, "conf", f))
+			Expect(err).NotTo(HaveOccurred())
+
+			d := xml.NewDecoder(in)
+			for err == nil {
+				_, err = d.Token()
+			}
+			Expect(err).To(MatchError(io.EOF), f)
+			Expect(in.Close()).To(Succeed())
+		}
+	})
+
+	it("adds session configuration when missing", func() {
+		Expect(os.WriteFile(filepath.Join(path, "conf", "web.xml"), []byte("<web-app>\n</web-app>\n"), 0644)).To(Succeed())
+
+		Expect(tomcat.Hardening{Profile: tomcat.HardeningStrict}.Configure(path)).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(path, "conf", "web.xml"))).To(ContainSubstring("<session-config>\n        <cookie-config>"))
+	})
+
+	it("adds only missing attributes", func() {
+		Expect(os.WriteFile(filepath.Join(path, "conf", "server.xml"), []byte(`<Server>
+    <Connector
+        port='8080' xpoweredBy='true'/>
+    <Connector	port='8443' allowTrace="true"/>
+</Server>
+`), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "conf", "context.xml"),
+			[]byte("<Context>\n    <CookieProcessor sameSiteCookies='none'/>\n</Context>\n"), 0644)).To(Succeed())
+
+		Expect(tomcat.Hardening{Profile: tomcat.HardeningStrict, SameSiteCookies: "strict"}.Configure(path)).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(path, "conf", "server.xml"))).To(Equal([]byte(`<Server>
+    <Connector allowTrace='false'
+        port='8080' xpoweredBy='true'/>
+    <Connector xpoweredBy='false'	port='8443' allowTrace="true"/>
+</Server>
+`)))
+		Expect(os.ReadFile(filepath.Join(path, "conf", "context.xml"))).
+			To(Equal([]byte("<Context>\n    <CookieProcessor sameSiteCookies='none'/>\n</Context>\n")))
+	})
+
+	it("sets configured SameSite cookie attribute", func() {
+		Expect(tomcat.Hardening{Profile: tomcat.HardeningStrict, SameSiteCookies: "strict"}.Configure(path)).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(path, "conf", "context.xml"))).To(ContainSubstring("sameSiteCookies='strict'"))
+	})
+
+	it("fails without a Connector", func() {
+		Expect(os.WriteFile(filepath.Join(path, "conf", "server.xml"), []byte("<Server/>"), 0644)).To(Succeed())
+
+		Expect(tomcat.Hardening{Profile: tomcat.HardeningStrict}.Configure(path)).
+			To(MatchError(ContainSubstring("unable to find Connector")))
+	})
+}
@@ -19,6 +19,9 @@ package tomcat
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/buildpacks/libcnb"
 	"github.com/paketo-buildpacks/libpak"
@@ -26,9 +29,36 @@ import (
 	"github.com/paketo-buildpacks/libpak/crush"
 )
 
+// HomePrunePatterns are the files of the Tomcat distribution, relative to $CATALINA_HOME, that are not needed at
+// runtime.
+var HomePrunePatterns = []string{
+	"BUILDING.txt",
+	"CONTRIBUTING.md",
+	"README.md",
+	"RELEASE-NOTES",
+	"RUNNING.txt",
+	"bin/*.bat",
+	"bin/*.tar.gz",
+	"webapps/ROOT",
+	"webapps/docs",
+	"webapps/examples",
+	"webapps/host-manager",
+	"webapps/manager",
+}
+
+// DefaultWebappPrunePatterns returns the patterns that prune the default webapps from $CATALINA_HOME/webapps.
+func DefaultWebappPrunePatterns() []string {
+	var patterns []string
+	for _, w := range DefaultWebapps {
+		patterns = append(patterns, filepath.Join("webapps", w))
+	}
+	return patterns
+}
+
 type Home struct {
 	LayerContributor libpak.DependencyLayerContributor
 	Logger           bard.Logger
+	Prune            []string
 }
 
 func NewHome(dependency libpak.BuildpackDependency, cache libpak.DependencyCache) (Home, libcnb.BOMEntry) {
@@ -42,18 +72,76 @@ func NewHome(dependency libpak.BuildpackDependency, cache libpak.DependencyCache
 func (h Home) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
 	h.LayerContributor.Logger = h.Logger
 
+	if len(h.Prune) > 0 {
+		h.LayerContributor.ExpectedMetadata = map[string]interface{}{
+			"dependency": h.LayerContributor.Dependency,
+			"prune":      h.Prune,
+		}
+	}
+
 	return h.LayerContributor.Contribute(layer, func(artifact *os.File) (libcnb.Layer, error) {
 		h.Logger.Bodyf("Expanding to %s", layer.Path)
 		if err := crush.Extract(artifact, layer.Path, 1); err != nil {
 			return libcnb.Layer{}, fmt.Errorf("unable to expand Tomcat\n%w", err)
 		}
 
+		if err := h.prune(layer); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to prune Tomcat\n%w", err)
+		}
+
 		layer.LaunchEnvironment.Default("CATALINA_HOME", layer.Path)
+		layer.LaunchEnvironment.Default("BPI_TOMCAT_VERSION", h.LayerContributor.Dependency.Version)
+
+		d := DependencySBOM{Dependencies: []libpak.BuildpackDependency{h.LayerContributor.Dependency}, Logger: h.Logger}
+		if err := d.WriteTo(layer, libcnb.CycloneDXJSON, libcnb.SPDXJSON); err != nil {
+			return libcnb.Layer{}, err
+		}
 
 		return layer, nil
 	})
 }
 
+// prune removes the files matching Prune and records them, one per line, in PRUNED.txt.
+func (h Home) prune(layer libcnb.Layer) error {
+	if len(h.Prune) == 0 {
+		return nil
+	}
+
+	var removed []string
+	for _, p := range h.Prune {
+		matches, err := filepath.Glob(filepath.Join(layer.Path, p))
+		if err != nil {
+			return fmt.Errorf("unable to match %s\n%w", p, err)
+		}
+
+		for _, m := range matches {
+			if err := os.RemoveAll(m); err != nil {
+				return fmt.Errorf("unable to remove %s\n%w", m, err)
+			}
+
+			r, err := filepath.Rel(layer.Path, m)
+			if err != nil {
+				return fmt.Errorf("unable to relativize %s\n%w", m, err)
+			}
+			removed = append(removed, r)
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	sort.Strings(removed)
+
+	file := filepath.Join(layer.Path, "PRUNED.txt")
+	h.Logger.Bodyf("Removed %d files not needed at runtime, listed in %s", len(removed), file)
+	if err := os.WriteFile(file, []byte(strings.Join(removed, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return nil
+}
+
 func (h Home) Name() string {
 	return h.LayerContributor.LayerName()
 }
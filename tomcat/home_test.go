@@ -68,5 +68,42 @@ func testHome(t *testing.T, context spec.G, it spec.S) {
 		Expect(layer.Launch).To(BeTrue())
 		Expect(filepath.Join(layer.Path, "fixture-marker")).To(BeARegularFile())
 		Expect(layer.LaunchEnvironment["CATALINA_HOME.default"]).To(Equal(layer.Path))
+		Expect(layer.LaunchEnvironment["BPI_TOMCAT_VERSION.default"]).To(Equal(dep.Version))
+		Expect(layer.SBOMPath(libcnb.SyftJSON)).To(BeARegularFile())
+		Expect(layer.SBOMPath(libcnb.CycloneDXJSON)).To(BeARegularFile())
+		Expect(layer.SBOMPath(libcnb.SPDXJSON)).To(BeARegularFile())
+	})
+
+	it("prunes catalina home", func() {
+		dep := libpak.BuildpackDependency{
+			ID:     "tomcat",
+			URI:    "https://localhost/stub-tomcat-pruned.tar.gz",
+			SHA256: "4edd4d738f2f6e15aa1c565f64b441b225d47c3156ab6b28538899bada75d4ed",
+			PURL:   "pkg:generic/tomcat@1.1.1",
+			CPEs:   []string{"cpe:2.3:a:apache:tomcat:1.1.1:*:*:*:*:*:*:*"},
+		}
+		dc := libpak.DependencyCache{CachePath: "testdata"}
+
+		h, _ := tomcat.NewHome(dep, dc)
+		h.Prune = tomcat.HomePrunePatterns
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = h.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(layer.Path, "LICENSE")).To(BeARegularFile())
+		Expect(filepath.Join(layer.Path, "bin", "catalina.sh")).To(BeARegularFile())
+		Expect(filepath.Join(layer.Path, "lib", "catalina.jar")).To(BeARegularFile())
+		Expect(filepath.Join(layer.Path, "webapps")).To(BeADirectory())
+		Expect(filepath.Join(layer.Path, "webapps", "examples")).NotTo(BeAnExistingFile())
+		Expect(os.ReadFile(filepath.Join(layer.Path, "PRUNED.txt"))).To(Equal([]byte(`RUNNING.txt
+bin/catalina.bat
+bin/tomcat-native.tar.gz
+webapps/ROOT
+webapps/examples
+`)))
+		Expect(layer.Metadata).To(HaveKey("prune"))
 	})
 }
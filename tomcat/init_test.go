@@ -25,9 +25,28 @@ import (
 
 func TestUnit(t *testing.T) {
 	suite := spec.New("tomcat", spec.Report(report.Terminal{}))
+	suite("Advisories", testAdvisories)
+	suite("AppCDS", testAppCDS)
+	suite("ApplicationLayout", testApplicationLayout)
 	suite("Base", testBase)
 	suite("Build", testBuild)
+	suite("ConfigurationValidator", testConfigurationValidator)
+	suite("ConnectorSizing", testConnectorSizing)
+	suite("Contexts", testContexts)
+	suite("DependencySBOM", testDependencySBOM)
+	suite("DeploymentDescriptor", testDeploymentDescriptor)
 	suite("Detect", testDetect)
+	suite("ExecutableWar", testExecutableWar)
+	suite("ExtraLibraries", testExtraLibraries)
+	suite("Hardening", testHardening)
 	suite("Home", testHome)
+	suite("JavaVersion", testJavaVersion)
+	suite("Manager", testManager)
+	suite("OpenTelemetry", testOpenTelemetry)
+	suite("RemoteIP", testRemoteIP)
+	suite("StaticAssets", testStaticAssets)
+	suite("SupportComponents", testSupportComponents)
+	suite("WarFiles", testWarFiles)
+	suite("WebappSBOM", testWebappSBOM)
 	suite.Run(t)
 }
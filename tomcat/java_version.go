@@ -0,0 +1,130 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/paketo-buildpacks/libpak"
+)
+
+// tomcatJavaVersions maps each Tomcat line, newest first, to the minimum Java version it runs on.
+var tomcatJavaVersions = []struct {
+	Tomcat string
+	Java   int
+}{
+	{"11.0", 17},
+	{"10.1", 11},
+	{"9.0", 8},
+}
+
+// MinimumJavaVersion returns the minimum Java version required by a Tomcat dependency, or zero if it is unknown.
+func MinimumJavaVersion(tomcat libpak.BuildpackDependency) (int, error) {
+	v, err := semver.NewVersion(tomcat.Version)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse version %s of %s\n%w", tomcat.Version, tomcat.ID, err)
+	}
+
+	for _, t := range tomcatJavaVersions {
+		if !v.LessThan(semver.MustParse(t.Tomcat)) {
+			return t.Java, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// JavaMajorVersion returns the major version of a Java version such as 17, 17.*, 11.0.2 or 1.8.
+func JavaMajorVersion(version string) (int, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(version), "1.")
+	if i := strings.IndexAny(s, ".*+-_"); i >= 0 {
+		s = s[:i]
+	}
+
+	major, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse Java version %q", version)
+	}
+
+	return major, nil
+}
+
+// CheckJavaVersion returns an error if a requested Java version is older than the minimum required by Tomcat.
+func CheckJavaVersion(tomcat libpak.BuildpackDependency, requested string) error {
+	return checkJavaVersion(tomcat, requested, "BP_JVM_VERSION")
+}
+
+// CheckJavaHome returns an error if the JRE in javaHome is older than the minimum required by Tomcat.
+func CheckJavaHome(tomcat libpak.BuildpackDependency, javaHome string) error {
+	version, err := javaRelease(javaHome, "JAVA_VERSION")
+	if err != nil {
+		return err
+	}
+
+	return checkJavaVersion(tomcat, version, fmt.Sprintf("the JRE in %s", javaHome))
+}
+
+func checkJavaVersion(tomcat libpak.BuildpackDependency, version string, source string) error {
+	minimum, err := MinimumJavaVersion(tomcat)
+	if err != nil {
+		return err
+	} else if minimum == 0 {
+		return nil
+	}
+
+	major, err := JavaMajorVersion(version)
+	if err != nil {
+		return err
+	}
+
+	if major < minimum {
+		return fmt.Errorf("Tomcat %s requires Java %d or later, but %s is %s\n"+
+			"set BP_JVM_VERSION to %d or later, or set BP_TOMCAT_VERSION to a Tomcat line that supports Java %d",
+			tomcat.Version, minimum, source, version, minimum, major)
+	}
+
+	return nil
+}
+
+// javaRelease returns the value of the first of keys set in the release file of the JRE in javaHome.
+func javaRelease(javaHome string, keys ...string) (string, error) {
+	file := filepath.Join(javaHome, "release")
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	values := map[string]string{}
+	for _, line := range strings.Split(string(b), "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			values[k] = strings.Trim(v, `"`)
+		}
+	}
+
+	for _, k := range keys {
+		if v, ok := values[k]; ok && v != "" {
+			return v, nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to find %s in %s", strings.Join(keys, " or "), file)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testJavaVersion(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+	)
+
+	it("returns minimum Java version of Tomcat", func() {
+		Expect(tomcat.MinimumJavaVersion(libpak.BuildpackDependency{ID: "tomcat", Version: "9.0.121"})).To(Equal(8))
+		Expect(tomcat.MinimumJavaVersion(libpak.BuildpackDependency{ID: "tomcat", Version: "10.1.59"})).To(Equal(11))
+		Expect(tomcat.MinimumJavaVersion(libpak.BuildpackDependency{ID: "tomcat", Version: "11.0.25"})).To(Equal(17))
+		Expect(tomcat.MinimumJavaVersion(libpak.BuildpackDependency{ID: "tomcat", Version: "1.1.1"})).To(Equal(0))
+	})
+
+	it("parses Java major version", func() {
+		Expect(tomcat.JavaMajorVersion("17")).To(Equal(17))
+		Expect(tomcat.JavaMajorVersion("17.*")).To(Equal(17))
+		Expect(tomcat.JavaMajorVersion("11.0.2")).To(Equal(11))
+		Expect(tomcat.JavaMajorVersion("1.8")).To(Equal(8))
+
+		_, err := tomcat.JavaMajorVersion("latest")
+		Expect(err).To(MatchError(`unable to parse Java version "latest"`))
+	})
+
+	it("accepts compatible Java version", func() {
+		Expect(tomcat.CheckJavaVersion(libpak.BuildpackDependency{ID: "tomcat", Version: "11.0.25"}, "21")).To(Succeed())
+		Expect(tomcat.CheckJavaVersion(libpak.BuildpackDependency{ID: "tomcat", Version: "9.0.121"}, "1.8")).To(Succeed())
+	})
+
+	it("checks the JRE in $JAVA_HOME", func() {
+		javaHome, err := os.MkdirTemp("", "java-home")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(javaHome)
+
+		Expect(os.WriteFile(filepath.Join(javaHome, "release"), []byte("JAVA_VERSION=\"11.0.24\"\n"), 0644)).To(Succeed())
+
+		Expect(tomcat.CheckJavaHome(libpak.BuildpackDependency{ID: "tomcat", Version: "10.1.59"}, javaHome)).To(Succeed())
+		Expect(tomcat.CheckJavaHome(libpak.BuildpackDependency{ID: "tomcat", Version: "11.0.25"}, javaHome)).
+			To(MatchError(ContainSubstring(fmt.Sprintf("Tomcat 11.0.25 requires Java 17 or later, but the JRE in %s is 11.0.24", javaHome))))
+	})
+
+	it("rejects incompatible Java version", func() {
+		Expect(tomcat.CheckJavaVersion(libpak.BuildpackDependency{ID: "tomcat", Version: "11.0.25"}, "11")).
+			To(MatchError(ContainSubstring("Tomcat 11.0.25 requires Java 17 or later, but BP_JVM_VERSION is 11")))
+	})
+}
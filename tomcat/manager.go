@@ -0,0 +1,66 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+// DefaultManagerContextPath is the context path the manager webapp is mounted at when one is not configured.
+const DefaultManagerContextPath = "/manager"
+
+// Manager mounts the manager webapp of $CATALINA_HOME using a context descriptor in
+// $CATALINA_BASE/conf/Catalina/localhost.  Users are read from the file written at launch by the manager-users helper
+// and access is restricted to the CIDRs it configures.
+type Manager struct {
+	ContextName string
+	Logger      bard.Logger
+}
+
+func (m Manager) Contribute(catalinaBase string) error {
+	for _, f := range []string{m.ContextName, m.ContextName + ".war"} {
+		file := filepath.Join(catalinaBase, "webapps", f)
+		if _, err := os.Lstat(file); err == nil {
+			return fmt.Errorf("manager context %s conflicts with %s", ContextURL(m.ContextName), file)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("unable to stat %s\n%w", file, err)
+		}
+	}
+
+	m.Logger.Headerf("Mounting manager at %s", ContextURL(m.ContextName))
+
+	file := filepath.Join(catalinaBase, "conf", "Catalina", "localhost", m.ContextName+".xml")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s\n%w", filepath.Dir(file), err)
+	}
+
+	if err := os.WriteFile(file, []byte(`<?xml version='1.0' encoding='utf-8'?>
+<Context docBase='${catalina.home}/webapps/manager' antiResourceLocking='false' privileged='true'>
+    <Valve className='org.apache.catalina.valves.RemoteCIDRValve' allow='${tomcat.manager.allow}'/>
+    <Realm className='org.apache.catalina.realm.MemoryRealm' pathname='${tomcat.manager.users}'/>
+    <Manager sessionAttributeValueClassNameFilter='java\.lang\.(?:Boolean|Integer|Long|Number|String)|org\.apache\.catalina\.filters\.CsrfPreventionFilter\$LruCache(?:\$1)?|java\.util\.(?:Linked)?HashMap'/>
+</Context>
+`), 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testManager(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		path, err = os.MkdirTemp("", "manager")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(path, "webapps", "ROOT"), 0755)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("contributes manager context descriptor", func() {
+		m := tomcat.Manager{ContextName: tomcat.ContextName("/ops/manager")}
+		Expect(m.Contribute(path)).To(Succeed())
+
+		b, err := os.ReadFile(filepath.Join(path, "conf", "Catalina", "localhost", "ops#manager.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring("docBase='${catalina.home}/webapps/manager'"))
+		Expect(string(b)).To(ContainSubstring("<Valve className='org.apache.catalina.valves.RemoteCIDRValve' allow='${tomcat.manager.allow}'/>"))
+		Expect(string(b)).To(ContainSubstring("<Realm className='org.apache.catalina.realm.MemoryRealm' pathname='${tomcat.manager.users}'/>"))
+	})
+
+	it("fails when context is already mounted", func() {
+		m := tomcat.Manager{ContextName: "ROOT"}
+		Expect(m.Contribute(path)).To(MatchError(ContainSubstring("manager context / conflicts with")))
+	})
+}
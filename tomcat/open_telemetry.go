@@ -0,0 +1,108 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sherpa"
+)
+
+var accessLoggingPattern = regexp.MustCompile(`(className=['"]org\.cloudfoundry\.tomcat\.logging\.access\.CloudFoundryAccessLoggingValve['"]\s+pattern='[^']*)'`)
+
+// NewOpenTelemetryAgentDependency resolves the opentelemetry-javaagent dependency in buildpack.toml at
+// $BP_TOMCAT_OTEL_AGENT_VERSION, or the agent at $BP_TOMCAT_OTEL_AGENT_URI, which must be verified by
+// $BP_TOMCAT_OTEL_AGENT_SHA256.
+func NewOpenTelemetryAgentDependency(configurationResolver libpak.ConfigurationResolver, dependencyResolver libpak.DependencyResolver, stackID string) (libpak.BuildpackDependency, error) {
+	v, _ := configurationResolver.Resolve("BP_TOMCAT_OTEL_AGENT_VERSION")
+
+	uri, ok := configurationResolver.Resolve("BP_TOMCAT_OTEL_AGENT_URI")
+	if !ok {
+		d, err := dependencyResolver.Resolve("opentelemetry-javaagent", v)
+		if err != nil {
+			return libpak.BuildpackDependency{}, fmt.Errorf("unable to find dependency\n%w", err)
+		}
+		return d, nil
+	}
+
+	s, ok := configurationResolver.Resolve("BP_TOMCAT_OTEL_AGENT_SHA256")
+	if !ok || s == "" {
+		return libpak.BuildpackDependency{}, fmt.Errorf("BP_TOMCAT_OTEL_AGENT_SHA256 must be set with BP_TOMCAT_OTEL_AGENT_URI")
+	}
+	if v == "" {
+		v = "custom"
+	}
+
+	return libpak.BuildpackDependency{
+		ID:      "opentelemetry-javaagent",
+		Name:    "OpenTelemetry Java Agent",
+		Version: v,
+		URI:     uri,
+		SHA256:  s,
+		Stacks:  []string{stackID},
+	}, nil
+}
+
+// OpenTelemetry contributes the OpenTelemetry Java agent to $CATALINA_BASE/otel, for the opentelemetry helper to attach
+// at launch, and logs the W3C traceparent request header in the access log.
+type OpenTelemetry struct {
+	Dependency      libpak.BuildpackDependency
+	DependencyCache libpak.DependencyCache
+	Logger          bard.Logger
+}
+
+func (o OpenTelemetry) Contribute(catalinaBase string) (string, error) {
+	o.Logger.Header(color.BlueString("%s %s", o.Dependency.Name, o.Dependency.Version))
+
+	artifact, err := o.DependencyCache.Artifact(o.Dependency)
+	if err != nil {
+		return "", fmt.Errorf("unable to get dependency %s\n%w", o.Dependency.ID, err)
+	}
+	defer artifact.Close()
+
+	o.Logger.Bodyf("Copying to %s/otel", catalinaBase)
+
+	agent := filepath.Join(catalinaBase, "otel", "opentelemetry-javaagent.jar")
+	if err := sherpa.CopyFile(artifact, agent); err != nil {
+		return "", fmt.Errorf("unable to copy %s to %s\n%w", filepath.Base(o.Dependency.URI), agent, err)
+	}
+
+	file := filepath.Join(catalinaBase, "conf", "server.xml")
+	in, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	if !accessLoggingPattern.Match(in) {
+		o.Logger.Bodyf("WARNING: no access logging pattern found in %s, traceparent will not be logged", file)
+		return agent, nil
+	}
+
+	o.Logger.Body("Adding traceparent to the access log")
+	out := accessLoggingPattern.ReplaceAll(in, []byte("${1} traceparent:%{traceparent}i'"))
+	if err := os.WriteFile(file, out, 0644); err != nil {
+		return "", fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return agent, nil
+}
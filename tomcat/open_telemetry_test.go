@@ -0,0 +1,114 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testOpenTelemetry(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		path, err = os.MkdirTemp("", "open-telemetry")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(path, "conf"), 0755)).To(Succeed())
+		b, err := os.ReadFile(filepath.Join("..", "resources", "server.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(path, "conf", "server.xml"), b, 0644)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("contributes the agent and logs traceparent", func() {
+		o := tomcat.OpenTelemetry{
+			Dependency: libpak.BuildpackDependency{
+				ID:     "tomcat-access-logging-support",
+				URI:    "https://localhost/stub-tomcat-access-logging-support.jar",
+				SHA256: "d723bfe2ba67dfa92b24e3b6c7b2d0e6a963de7313350e306d470e44e330a5d2",
+				PURL:   "pkg:generic/tomcat-access-logging-support@3.3.0",
+				CPEs:   []string{"cpe:2.3:a:cloudfoundry:tomcat-access-logging-support:3.3.0:*:*:*:*:*:*:*"},
+			},
+			DependencyCache: libpak.DependencyCache{CachePath: "testdata"},
+		}
+
+		agent, err := o.Contribute(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(agent).To(Equal(filepath.Join(path, "otel", "opentelemetry-javaagent.jar")))
+		Expect(agent).To(BeARegularFile())
+		Expect(os.ReadFile(filepath.Join(path, "conf", "server.xml"))).
+			To(ContainSubstring("vcap_request_id:%{X-Vcap-Request-Id}i traceparent:%{traceparent}i'"))
+	})
+
+	context("NewOpenTelemetryAgentDependency", func() {
+		var dr libpak.DependencyResolver
+
+		it.Before(func() {
+			dr = libpak.DependencyResolver{
+				Dependencies: []libpak.BuildpackDependency{
+					{ID: "opentelemetry-javaagent", Version: "2.9.0", SHA256: "test-sha256", Stacks: []string{"test-stack-id"}},
+				},
+				StackID: "test-stack-id",
+			}
+		})
+
+		it("resolves the agent dependency", func() {
+			Expect(tomcat.NewOpenTelemetryAgentDependency(libpak.ConfigurationResolver{}, dr, "test-stack-id")).
+				To(Equal(dr.Dependencies[0]))
+		})
+
+		it("creates a custom agent dependency", func() {
+			t.Setenv("BP_TOMCAT_OTEL_AGENT_URI", "https://localhost/opentelemetry-javaagent.jar")
+			t.Setenv("BP_TOMCAT_OTEL_AGENT_SHA256", "custom-sha256")
+			t.Setenv("BP_TOMCAT_OTEL_AGENT_VERSION", "2.0.0")
+
+			Expect(tomcat.NewOpenTelemetryAgentDependency(libpak.ConfigurationResolver{}, dr, "test-stack-id")).
+				To(Equal(libpak.BuildpackDependency{
+					ID:      "opentelemetry-javaagent",
+					Name:    "OpenTelemetry Java Agent",
+					Version: "2.0.0",
+					URI:     "https://localhost/opentelemetry-javaagent.jar",
+					SHA256:  "custom-sha256",
+					Stacks:  []string{"test-stack-id"},
+				}))
+		})
+
+		it("requires a SHA256 with a custom agent", func() {
+			t.Setenv("BP_TOMCAT_OTEL_AGENT_URI", "https://localhost/opentelemetry-javaagent.jar")
+
+			_, err := tomcat.NewOpenTelemetryAgentDependency(libpak.ConfigurationResolver{}, dr, "test-stack-id")
+			Expect(err).To(MatchError("BP_TOMCAT_OTEL_AGENT_SHA256 must be set with BP_TOMCAT_OTEL_AGENT_URI"))
+		})
+	})
+}
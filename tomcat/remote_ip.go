@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
+)
+
+// DefaultInternalProxies is the regular expression of the private, loopback and link-local addresses that
+// RemoteIpValve trusts by default.  Placeholder defaults cannot contain a closing brace, so quantifiers such as {1,3}
+// are not used.
+const DefaultInternalProxies = `10\.\d+\.\d+\.\d+|192\.168\.\d+\.\d+|169\.254\.\d+\.\d+|127\.\d+\.\d+\.\d+|` +
+	`100\.(?:6[4-9]|[7-9]\d|1[01]\d|12[0-7])\.\d+\.\d+|172\.(?:1[6-9]|2\d|3[01])\.\d+\.\d+|` +
+	`0:0:0:0:0:0:0:1|::1|fe[89ab][0-9a-f]:.*|f[cd][0-9a-f]+:.*`
+
+// RemoteIPAttributes are the attributes of RemoteIpValve the remote-ip helper configures, and their defaults.
+var RemoteIPAttributes = [][2]string{
+	{"internalProxies", DefaultInternalProxies},
+	{"trustedProxies", ""},
+	{"remoteIpHeader", "x-forwarded-for"},
+	{"protocolHeader", "x-forwarded-proto"},
+	{"hostHeader", ""},
+	{"portHeader", ""},
+}
+
+// RemoteIP replaces the attributes of the RemoteIpValves in $CATALINA_BASE/conf/server.xml with placeholders, so the
+// remote-ip helper can configure them at launch with tomcat.remoteIp.* system properties.  A placeholder defaults to
+// the value the Valve sets, or RemoteIpValve's default.  Values that contain a closing brace cannot be placeholder
+// defaults and are left unchanged.
+type RemoteIP struct {
+	Logger bard.Logger
+}
+
+func (r RemoteIP) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	file := filepath.Join(layer.Path, "conf", "server.xml")
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	r.Logger.Header(color.BlueString("Tomcat RemoteIpValve"))
+	r.Logger.Body("Adding placeholders to RemoteIpValves in server.xml")
+
+	b = util.Element("Valve").ReplaceAllFunc(b, func(valve []byte) []byte {
+		s := string(valve)
+		if className, _ := util.Attribute(s, "className"); className != "org.apache.catalina.valves.RemoteIpValve" {
+			return valve
+		}
+
+		var missing [][2]string
+		for _, a := range RemoteIPAttributes {
+			value, ok := util.Attribute(s, a[0])
+			if !ok {
+				missing = append(missing, [2]string{a[0], fmt.Sprintf("${tomcat.remoteIp.%s:-%s}", a[0], a[1])})
+			} else if strings.Contains(value, "${") {
+				continue
+			} else if strings.ContainsAny(value, `}'"`) {
+				r.Logger.Bodyf("WARNING: %s of RemoteIpValve contains a brace or quote and cannot be configured at launch", a[0])
+			} else {
+				s = util.SetAttribute(s, a[0], fmt.Sprintf("${tomcat.remoteIp.%s:-%s}", a[0], value))
+			}
+		}
+
+		return []byte(util.AddAttributes(s, missing))
+	})
+
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		return nil, fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return nil, nil
+}
+
+func (RemoteIP) Name() string {
+	return "remote ip"
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testRemoteIP(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layer libcnb.Layer
+	)
+
+	it.Before(func() {
+		var err error
+		layer.Path, err = os.MkdirTemp("", "remote-ip")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(layer.Path, "conf"), 0755)).To(Succeed())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layer.Path)).To(Succeed())
+	})
+
+	it("replaces RemoteIpValve attributes with placeholders", func() {
+		Expect(os.WriteFile(filepath.Join(layer.Path, "conf", "server.xml"), []byte(`<Engine defaultHost='localhost' name='Catalina'>
+    <Valve protocolHeader="x-scheme" className='org.apache.catalina.valves.RemoteIpValve' requestAttributesEnabled='true'>
+    </Valve>
+    <Valve className='org.apache.catalina.valves.AccessLogValve'/>
+</Engine>
+`), 0644)).To(Succeed())
+
+		Expect(tomcat.RemoteIP{}.Contribute(layer)).To(BeNil())
+
+		Expect(os.ReadFile(filepath.Join(layer.Path, "conf", "server.xml"))).To(Equal([]byte(`<Engine defaultHost='localhost' name='Catalina'>
+    <Valve internalProxies='${tomcat.remoteIp.internalProxies:-` + tomcat.DefaultInternalProxies + `}' trustedProxies='${tomcat.remoteIp.trustedProxies:-}' remoteIpHeader='${tomcat.remoteIp.remoteIpHeader:-x-forwarded-for}' hostHeader='${tomcat.remoteIp.hostHeader:-}' portHeader='${tomcat.remoteIp.portHeader:-}' protocolHeader="${tomcat.remoteIp.protocolHeader:-x-scheme}" className='org.apache.catalina.valves.RemoteIpValve' requestAttributesEnabled='true'>
+    </Valve>
+    <Valve className='org.apache.catalina.valves.AccessLogValve'/>
+</Engine>
+`)))
+	})
+
+	it("leaves values that cannot be placeholder defaults", func() {
+		Expect(os.WriteFile(filepath.Join(layer.Path, "conf", "server.xml"), []byte(`<Valve className='org.apache.catalina.valves.RemoteIpValve' internalProxies='10\.\d{1,3}\.\d{1,3}\.\d{1,3}' remoteIpHeader='${proxy.header}'/>`), 0644)).To(Succeed())
+
+		Expect(tomcat.RemoteIP{}.Contribute(layer)).To(BeNil())
+
+		b, err := os.ReadFile(filepath.Join(layer.Path, "conf", "server.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring(`internalProxies='10\.\d{1,3}\.\d{1,3}\.\d{1,3}' remoteIpHeader='${proxy.header}'`))
+		Expect(string(b)).To(ContainSubstring(`trustedProxies='${tomcat.remoteIp.trustedProxies:-}'`))
+	})
+
+	it("ignores a missing server.xml", func() {
+		Expect(tomcat.RemoteIP{}.Contribute(layer)).To(BeNil())
+	})
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxInvalidIDCharacters = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// SPDXID converts an arbitrary identifier into a valid SPDX element identifier.
+func SPDXID(id string) string {
+	return "SPDXRef-Package-" + spdxInvalidIDCharacters.ReplaceAllString(id, "-")
+}
+
+// NewSPDXDocument creates a document describing packages.  The creation time is taken from $SOURCE_DATE_EPOCH when set
+// so that the document is reproducible.
+func NewSPDXDocument(name string, packages []SPDXPackage) SPDXDocument {
+	if packages == nil {
+		packages = []SPDXPackage{}
+	}
+
+	created := time.Date(1980, time.January, 1, 0, 0, 1, 0, time.UTC)
+	if s, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			created = time.Unix(i, 0).UTC()
+		}
+	}
+
+	d := SPDXDocument{
+		SPDXVersion:   "SPDX-2.3",
+		DataLicense:   "CC0-1.0",
+		SPDXID:        "SPDXRef-DOCUMENT",
+		Name:          name,
+		CreationInfo:  SPDXCreationInfo{Created: created.Format(time.RFC3339), Creators: []string{"Organization: Paketo", "Tool: apache-tomcat"}},
+		Packages:      packages,
+		Relationships: []SPDXRelationship{},
+	}
+
+	h := sha256.New()
+	for _, p := range packages {
+		_, _ = fmt.Fprintf(h, "%s@%s\n", p.SPDXID, p.VersionInfo)
+		d.Relationships = append(d.Relationships, SPDXRelationship{
+			SPDXElementID:      d.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: p.SPDXID,
+		})
+	}
+	d.DocumentNamespace = fmt.Sprintf("https://paketo.io/spdx/apache-tomcat/%s-%x", name, h.Sum(nil))
+
+	return d
+}
+
+func (s SPDXDocument) WriteTo(path string) error {
+	output, err := json.Marshal(&s)
+	if err != nil {
+		return fmt.Errorf("unable to marshal to JSON\n%w", err)
+	}
+
+	if err := os.WriteFile(path, output, 0644); err != nil {
+		return fmt.Errorf("unable to write to path %s\n%w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
+	"github.com/paketo-buildpacks/libpak/bard"
+)
+
+const StaticCachePrefix = "BP_TOMCAT_STATIC_CACHE_"
+
+// CompressibleExtensions are the extensions of static assets that are pre-compressed.
+var CompressibleExtensions = []string{
+	".css", ".csv", ".html", ".htm", ".js", ".json", ".map", ".mjs", ".svg", ".txt", ".wasm", ".xml",
+}
+
+// MinimumCompressibleSize is the size in bytes below which static assets are not pre-compressed.
+const MinimumCompressibleSize = 1024
+
+// StaticAssets pre-compresses the static assets of webapps with gzip and brotli so that DefaultServlet can serve the
+// compressed variants directly.  It changes the application rather than a layer, so it must run on every build.
+type StaticAssets struct {
+	Directories []string
+	Logger      bard.Logger
+}
+
+func (s StaticAssets) Precompress() error {
+	count := 0
+
+	for _, dir := range s.Directories {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if path != dir && (d.Name() == "WEB-INF" || d.Name() == "META-INF") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !d.Type().IsRegular() || !s.compressible(path) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return fmt.Errorf("unable to stat %s\n%w", path, err)
+			}
+			if info.Size() < MinimumCompressibleSize {
+				return nil
+			}
+
+			in, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("unable to read %s\n%w", path, err)
+			}
+
+			gz, err := compressGzip(in)
+			if err != nil {
+				return fmt.Errorf("unable to gzip %s\n%w", path, err)
+			}
+
+			br, err := compressBrotli(in)
+			if err != nil {
+				return fmt.Errorf("unable to brotli compress %s\n%w", path, err)
+			}
+
+			for ext, out := range map[string][]byte{".gz": gz, ".br": br} {
+				if len(out) >= len(in) {
+					continue
+				}
+				if err := os.WriteFile(path+ext, out, info.Mode().Perm()); err != nil {
+					return fmt.Errorf("unable to write %s\n%w", path+ext, err)
+				}
+			}
+
+			count++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("unable to pre-compress static assets in %s\n%w", dir, err)
+		}
+	}
+
+	s.Logger.Bodyf("Pre-compressed %d static assets", count)
+	return nil
+}
+
+func (StaticAssets) compressible(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range CompressibleExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func compressGzip(in []byte) ([]byte, error) {
+	var b bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&b, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(in); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func compressBrotli(in []byte) ([]byte, error) {
+	var b bytes.Buffer
+
+	w := brotli.NewWriterLevel(&b, brotli.BestCompression)
+	if _, err := io.Copy(w, bytes.NewReader(in)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// StaticCacheRule sets the expiry, and so the Cache-Control max-age, of responses matching URL patterns.
+type StaticCacheRule struct {
+	Expires  string
+	Name     string
+	Patterns []string
+}
+
+// ParseStaticCacheRules parses every $BP_TOMCAT_STATIC_CACHE_<NAME> variable of an environment.  Each value is a comma
+// separated list of URL patterns, an equals sign and an ExpiresFilter duration, e.g. /assets/*,*.js=access plus 1 year.
+func ParseStaticCacheRules(environment []string) ([]StaticCacheRule, error) {
+	var rules []StaticCacheRule
+
+	for _, e := range environment {
+		name, value, _ := strings.Cut(e, "=")
+		if !strings.HasPrefix(name, StaticCachePrefix) || len(name) == len(StaticCachePrefix) {
+			continue
+		}
+
+		patterns, expires, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(expires) == "" {
+			return nil, fmt.Errorf("%s must be <url-pattern>[,<url-pattern>...]=<duration>, found %q", name, value)
+		}
+
+		r := StaticCacheRule{
+			Expires: strings.TrimSpace(expires),
+			Name:    strings.ToLower(strings.TrimPrefix(name, StaticCachePrefix)),
+		}
+		for _, p := range strings.Split(patterns, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				r.Patterns = append(r.Patterns, p)
+			}
+		}
+		if len(r.Patterns) == 0 {
+			return nil, fmt.Errorf("%s must have at least one URL pattern", name)
+		}
+
+		rules = append(rules, r)
+	}
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+	return rules, nil
+}
+
+// StaticAssetConfiguration configures DefaultServlet in $CATALINA_BASE/conf/web.xml to serve pre-compressed assets and
+// adds an ExpiresFilter for each cache rule.
+type StaticAssetConfiguration struct {
+	CacheRules    []StaticCacheRule
+	Logger        bard.Logger
+	Precompressed bool
+}
+
+func (s StaticAssetConfiguration) Configure(webXML string) error {
+	if s.Precompressed {
+		s.Logger.Body("Enabling pre-compressed static assets in DefaultServlet")
+
+		if ok, err := util.InsertAfter(webXML, "<servlet-class>org.apache.catalina.servlets.DefaultServlet</servlet-class>", `
+        <init-param>
+            <param-name>precompressed</param-name>
+            <param-value>br=.br,gzip=.gz</param-value>
+        </init-param>`); err != nil {
+			return fmt.Errorf("unable to configure DefaultServlet in %s\n%w", webXML, err)
+		} else if !ok {
+			return fmt.Errorf("unable to find DefaultServlet in %s", webXML)
+		}
+	}
+
+	if len(s.CacheRules) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	for _, r := range s.CacheRules {
+		s.Logger.Bodyf("Expiring %s %s", strings.Join(r.Patterns, ", "), r.Expires)
+
+		_, _ = fmt.Fprintf(&b, `
+    <filter>
+        <filter-name>staticCache-%[1]s</filter-name>
+        <filter-class>org.apache.catalina.filters.ExpiresFilter</filter-class>
+        <init-param>
+            <param-name>ExpiresDefault</param-name>
+            <param-value>%[2]s</param-value>
+        </init-param>
+    </filter>
+    <filter-mapping>
+        <filter-name>staticCache-%[1]s</filter-name>`, escapeXML(r.Name), escapeXML(r.Expires))
+		for _, p := range r.Patterns {
+			_, _ = fmt.Fprintf(&b, `
+        <url-pattern>%s</url-pattern>`, escapeXML(p))
+		}
+		b.WriteString(`
+        <dispatcher>REQUEST</dispatcher>
+    </filter-mapping>
+`)
+	}
+
+	if ok, err := util.InsertBefore(webXML, "</web-app>", b.String()); err != nil {
+		return fmt.Errorf("unable to configure cache rules in %s\n%w", webXML, err)
+	} else if !ok {
+		return fmt.Errorf("unable to find </web-app> in %s", webXML)
+	}
+
+	return nil
+}
+
+// escapeXML escapes s for use as XML character data.
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testStaticAssets(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		var err error
+		path, err = os.MkdirTemp("", "static-assets")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	context("Precompress", func() {
+		content := []byte(strings.Repeat("body { color: red; }\n", 100))
+
+		it.Before(func() {
+			Expect(os.MkdirAll(filepath.Join(path, "css"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "css", "app.css"), content, 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "small.js"), []byte("x"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "image.png"), content, 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(path, "WEB-INF"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(path, "WEB-INF", "private.css"), content, 0644)).To(Succeed())
+		})
+
+		it("pre-compresses static assets", func() {
+			Expect(tomcat.StaticAssets{Directories: []string{path}}.Precompress()).To(Succeed())
+
+			in, err := os.Open(filepath.Join(path, "css", "app.css.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			defer in.Close()
+			gz, err := gzip.NewReader(in)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(io.ReadAll(gz)).To(Equal(content))
+
+			br, err := os.ReadFile(filepath.Join(path, "css", "app.css.br"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(io.ReadAll(brotli.NewReader(bytes.NewReader(br)))).To(Equal(content))
+		})
+
+		it("skips small, incompressible and private files", func() {
+			Expect(tomcat.StaticAssets{Directories: []string{path}}.Precompress()).To(Succeed())
+
+			Expect(filepath.Join(path, "small.js.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(path, "image.png.gz")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(path, "WEB-INF", "private.css.gz")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	it("parses static cache rules", func() {
+		Expect(tomcat.ParseStaticCacheRules([]string{
+			"PATH=/usr/bin",
+			"BP_TOMCAT_STATIC_CACHE_SCRIPTS=*.js=access plus 1 day",
+			"BP_TOMCAT_STATIC_CACHE_ASSETS=/assets/*, /images/*=access plus 1 year",
+		})).To(Equal([]tomcat.StaticCacheRule{
+			{Expires: "access plus 1 year", Name: "assets", Patterns: []string{"/assets/*", "/images/*"}},
+			{Expires: "access plus 1 day", Name: "scripts", Patterns: []string{"*.js"}},
+		}))
+
+		_, err := tomcat.ParseStaticCacheRules([]string{"BP_TOMCAT_STATIC_CACHE_ASSETS=/assets/*"})
+		Expect(err).To(MatchError(`BP_TOMCAT_STATIC_CACHE_ASSETS must be <url-pattern>[,<url-pattern>...]=<duration>, found "/assets/*"`))
+	})
+
+	context("Configure", func() {
+		var webXML string
+
+		it.Before(func() {
+			webXML = filepath.Join(path, "web.xml")
+			Expect(os.WriteFile(webXML, []byte(`<web-app>
+    <servlet>
+        <servlet-name>default</servlet-name>
+        <servlet-class>org.apache.catalina.servlets.DefaultServlet</servlet-class>
+    </servlet>
+</web-app>
+`), 0644)).To(Succeed())
+		})
+
+		it("enables pre-compressed assets", func() {
+			Expect(tomcat.StaticAssetConfiguration{Precompressed: true}.Configure(webXML)).To(Succeed())
+
+			Expect(os.ReadFile(webXML)).To(ContainSubstring(`<servlet-class>org.apache.catalina.servlets.DefaultServlet</servlet-class>
+        <init-param>
+            <param-name>precompressed</param-name>
+            <param-value>br=.br,gzip=.gz</param-value>
+        </init-param>`))
+		})
+
+		it("adds cache rules", func() {
+			Expect(tomcat.StaticAssetConfiguration{CacheRules: []tomcat.StaticCacheRule{
+				{Expires: "access plus 1 year", Name: "assets", Patterns: []string{"/assets/*", "*.css"}},
+			}}.Configure(webXML)).To(Succeed())
+
+			b, err := os.ReadFile(webXML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("<filter-name>staticCache-assets</filter-name>"))
+			Expect(string(b)).To(ContainSubstring("<filter-class>org.apache.catalina.filters.ExpiresFilter</filter-class>"))
+			Expect(string(b)).To(ContainSubstring("<param-value>access plus 1 year</param-value>"))
+			Expect(string(b)).To(ContainSubstring("<url-pattern>/assets/*</url-pattern>"))
+			Expect(string(b)).To(ContainSubstring("<url-pattern>*.css</url-pattern>"))
+			Expect(string(b)).To(HaveSuffix("</filter-mapping>\n</web-app>\n"))
+		})
+
+		it("escapes cache rules", func() {
+			Expect(tomcat.StaticAssetConfiguration{CacheRules: []tomcat.StaticCacheRule{
+				{Expires: "access plus 1 day</param-value>", Name: "assets", Patterns: []string{"/a&b/*", "*.<css>"}},
+			}}.Configure(webXML)).To(Succeed())
+
+			b, err := os.ReadFile(webXML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(ContainSubstring("<param-value>access plus 1 day&lt;/param-value&gt;</param-value>"))
+			Expect(string(b)).To(ContainSubstring("<url-pattern>/a&amp;b/*</url-pattern>"))
+			Expect(string(b)).To(ContainSubstring("<url-pattern>*.&lt;css&gt;</url-pattern>"))
+		})
+
+		it("fails without DefaultServlet", func() {
+			Expect(os.WriteFile(webXML, []byte("<web-app/>"), 0644)).To(Succeed())
+
+			Expect(tomcat.StaticAssetConfiguration{Precompressed: true}.Configure(webXML)).
+				To(MatchError(ContainSubstring("unable to find DefaultServlet")))
+		})
+	})
+}
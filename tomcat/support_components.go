@@ -0,0 +1,112 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/paketo-buildpacks/libpak"
+	"github.com/paketo-buildpacks/libpak/bard"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/internal/util"
+)
+
+const (
+	cloudFoundryConsoleHandler = "org.cloudfoundry.tomcat.logging.CloudFoundryConsoleHandler"
+	accessLoggingValve         = "org.cloudfoundry.tomcat.logging.access.CloudFoundryAccessLoggingValve"
+	lifecycleListener          = "org.cloudfoundry.tomcat.lifecycle.ApplicationStartupFailureDetectingLifecycleListener"
+)
+
+// SupportComponents removes the references to the Cloud Foundry support components that are not enabled from the
+// buildpack's configuration in $CATALINA_BASE/conf, leaving the behaviour of vanilla Tomcat in their place.
+type SupportComponents struct {
+	AccessLogging bool
+	Lifecycle     bool
+	Logger        bard.Logger
+	Logging       bool
+}
+
+func (s SupportComponents) Contribute(layer libcnb.Layer) ([]libpak.BuildpackDependency, error) {
+	if s.AccessLogging && s.Lifecycle && s.Logging {
+		return nil, nil
+	}
+
+	s.Logger.Header(color.BlueString("Disabled support components"))
+
+	file := filepath.Join(layer.Path, "conf", "server.xml")
+	if !s.AccessLogging {
+		s.Logger.Body("Removing CloudFoundryAccessLoggingValve from server.xml")
+		if err := s.remove(file, "Valve", accessLoggingValve); err != nil {
+			return nil, err
+		}
+	}
+
+	if !s.Lifecycle {
+		s.Logger.Body("Removing ApplicationStartupFailureDetectingLifecycleListener from server.xml")
+		if err := s.remove(file, "Listener", lifecycleListener); err != nil {
+			return nil, err
+		}
+	}
+
+	if !s.Logging {
+		if err := s.configureLogging(layer); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (s SupportComponents) configureLogging(layer libcnb.Layer) error {
+	s.Logger.Body("Replacing CloudFoundryConsoleHandler with ConsoleHandler in logging.properties")
+
+	file := filepath.Join(layer.Path, "conf", "logging.properties")
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	p := strings.ReplaceAll(string(b), cloudFoundryConsoleHandler, "java.util.logging.ConsoleHandler")
+	p += "java.util.logging.ConsoleHandler.formatter: org.apache.juli.OneLineFormatter\n"
+	if err := os.WriteFile(file, []byte(p), 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return writeSetenv(layer, s.Logger)
+}
+
+func (SupportComponents) remove(file string, name string, className string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read %s\n%w", file, err)
+	}
+
+	if err := os.WriteFile(file, []byte(util.RemoveElements(string(b), name, className)), 0644); err != nil {
+		return fmt.Errorf("unable to write file %s\n%w", file, err)
+	}
+
+	return nil
+}
+
+func (SupportComponents) Name() string {
+	return "support components"
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testSupportComponents(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		layer libcnb.Layer
+	)
+
+	it.Before(func() {
+		var err error
+		layer.Path, err = os.MkdirTemp("", "support-components")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(layer.Path, "conf"), 0755)).To(Succeed())
+		for _, f := range []string{"logging.properties", "server.xml"} {
+			b, err := os.ReadFile(filepath.Join("..", "resources", f))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(layer.Path, "conf", f), b, 0644)).To(Succeed())
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(layer.Path)).To(Succeed())
+	})
+
+	it("does not change configuration when all components are enabled", func() {
+		Expect(tomcat.SupportComponents{AccessLogging: true, Lifecycle: true, Logging: true}.Contribute(layer)).To(BeNil())
+
+		expected, err := os.ReadFile(filepath.Join("..", "resources", "server.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.ReadFile(filepath.Join(layer.Path, "conf", "server.xml"))).To(Equal(expected))
+	})
+
+	it("removes disabled components", func() {
+		t.Setenv("BPI_TOMCAT_ADDITIONAL_JARS", "/layers/test-buildpack/foo/bar.jar")
+
+		Expect(tomcat.SupportComponents{}.Contribute(layer)).To(BeNil())
+
+		b, err := os.ReadFile(filepath.Join(layer.Path, "conf", "server.xml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).NotTo(ContainSubstring("CloudFoundryAccessLoggingValve"))
+		Expect(string(b)).NotTo(ContainSubstring("ApplicationStartupFailureDetectingLifecycleListener"))
+		Expect(string(b)).To(ContainSubstring("<Valve className='org.apache.catalina.valves.RemoteIpValve'"))
+		Expect(string(b)).To(ContainSubstring("<Valve className='org.apache.catalina.valves.ErrorReportValve'"))
+
+		d := xml.NewDecoder(bytes.NewReader(b))
+		for err == nil {
+			_, err = d.Token()
+		}
+		Expect(err).To(MatchError(io.EOF))
+
+		b, err = os.ReadFile(filepath.Join(layer.Path, "conf", "logging.properties"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).NotTo(ContainSubstring("CloudFoundryConsoleHandler"))
+		Expect(string(b)).To(ContainSubstring("handlers: java.util.logging.ConsoleHandler"))
+		Expect(string(b)).To(ContainSubstring("java.util.logging.ConsoleHandler.formatter: org.apache.juli.OneLineFormatter"))
+
+		Expect(os.ReadFile(filepath.Join(layer.Path, "bin", "setenv.sh"))).
+			To(Equal([]byte(`CLASSPATH="/layers/test-buildpack/foo/bar.jar"`)))
+	})
+
+	it("removes disabled components regardless of attribute order and quoting", func() {
+		Expect(os.WriteFile(filepath.Join(layer.Path, "conf", "server.xml"), []byte(`<Server>
+    <Listener
+        className="org.cloudfoundry.tomcat.lifecycle.ApplicationStartupFailureDetectingLifecycleListener"/>
+    <Service>
+        <Valve enabled='true' className="org.cloudfoundry.tomcat.logging.access.CloudFoundryAccessLoggingValve"></Valve>
+        <Valve className='org.apache.catalina.valves.ErrorReportValve'/>
+    </Service>
+</Server>
+`), 0644)).To(Succeed())
+
+		Expect(tomcat.SupportComponents{Logging: true}.Contribute(layer)).To(BeNil())
+
+		Expect(os.ReadFile(filepath.Join(layer.Path, "conf", "server.xml"))).To(Equal([]byte(`<Server>
+    <Service>
+        <Valve className='org.apache.catalina.valves.ErrorReportValve'/>
+    </Service>
+</Server>
+`)))
+	})
+
+	it("does not write setenv.sh without additional JARs", func() {
+		Expect(tomcat.SupportComponents{}.Contribute(layer)).To(BeNil())
+
+		Expect(filepath.Join(layer.Path, "bin", "setenv.sh")).NotTo(BeAnExistingFile())
+	})
+}
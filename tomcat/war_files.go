@@ -0,0 +1,68 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/crush"
+)
+
+// WarFiles explodes the WAR files at the top level of the application.  It changes the application rather than a
+// layer, so it must run on every build regardless of whether the catalina-base layer is reused.
+type WarFiles struct {
+	ApplicationPath string
+	Logger          bard.Logger
+}
+
+func (w WarFiles) Explode() error {
+	warFiles, err := filepath.Glob(filepath.Join(w.ApplicationPath, "*.war"))
+	if err != nil {
+		return err
+	}
+
+	for _, warFilePath := range warFiles {
+		w.Logger.Debugf("Extracting: %s\n", warFilePath)
+
+		if _, err := os.Stat(warFilePath); err == nil {
+			in, err := os.Open(warFilePath)
+			if err != nil {
+				return fmt.Errorf("An error occurred while extracting %s: %s\n", warFilePath, err)
+			}
+			defer in.Close()
+
+			targetDir := strings.TrimSuffix(warFilePath, filepath.Ext(warFilePath))
+			if err := os.MkdirAll(targetDir, 0755); err != nil {
+				return fmt.Errorf("An error occurred while extracting %s: %s\n", warFilePath, err)
+			}
+
+			if err := crush.Extract(in, targetDir, 0); err != nil {
+				return fmt.Errorf("An error occurred while extracting %s: %s\n", warFilePath, err)
+			}
+
+			err = os.Remove(warFilePath)
+			if err != nil {
+				return fmt.Errorf("An error occurred while removing the .war file: %s\n", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testWarFiles(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		files = []string{"api.war", "ui.war"}
+		path  string
+	)
+
+	it.Before(func() {
+		var err error
+
+		path, err = os.MkdirTemp("", "war-files")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, file := range files {
+			in, err := os.Open(filepath.Join("testdata", "warfiles", file))
+			Expect(err).NotTo(HaveOccurred())
+
+			out, err := os.OpenFile(filepath.Join(path, file), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = io.Copy(out, in)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(in.Close()).To(Succeed())
+			Expect(out.Close()).To(Succeed())
+		}
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(path)).To(Succeed())
+	})
+
+	it("Multiple war files have been exploded in application path", func() {
+		Expect(tomcat.WarFiles{ApplicationPath: path}.Explode()).To(Succeed())
+
+		for _, file := range files {
+			targetDir := strings.TrimSuffix(file, filepath.Ext(file))
+			Expect(filepath.Join(path, targetDir, "META-INF", "MANIFEST.MF")).To(BeARegularFile())
+			Expect(filepath.Join(path, file)).NotTo(BeAnExistingFile())
+		}
+	})
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/buildpacks/libcnb"
+	"github.com/heroku/color"
+	"github.com/magiconair/properties"
+	"github.com/paketo-buildpacks/libjvm"
+	"github.com/paketo-buildpacks/libpak/bard"
+	"github.com/paketo-buildpacks/libpak/sbom"
+)
+
+// WebappSBOM inventories the WEB-INF/lib JARs of each deployed webapp.  It is contributed on every build as the
+// application contents change independently of the Tomcat layers.
+type WebappSBOM struct {
+	ApplicationPath string
+	ContextPath     string
+	Logger          bard.Logger
+	WarFilesExist   bool
+}
+
+// WebappLibrary is a JAR found in the WEB-INF/lib directory of a webapp.
+type WebappLibrary struct {
+	ArtifactID  string
+	ContextPath string
+	GroupID     string
+	Name        string
+	Path        string
+	Version     string
+}
+
+func (w WebappLibrary) PURL() string {
+	if w.GroupID == "" || w.ArtifactID == "" || w.Version == "" {
+		return ""
+	}
+	return fmt.Sprintf("pkg:maven/%s/%s@%s", w.GroupID, w.ArtifactID, w.Version)
+}
+
+type webappSyftArtifact struct {
+	sbom.SyftArtifact
+	MetadataType string
+	Metadata     map[string]string
+}
+
+type webappSyftDependency struct {
+	Artifacts  []webappSyftArtifact
+	Source     sbom.SyftSource
+	Descriptor sbom.SyftDescriptor
+	Schema     sbom.SyftSchema
+}
+
+func NewWebappSBOM(applicationPath string, contextPath string, warFilesExist bool) WebappSBOM {
+	return WebappSBOM{
+		ApplicationPath: applicationPath,
+		ContextPath:     contextPath,
+		WarFilesExist:   warFilesExist,
+	}
+}
+
+func (w WebappSBOM) Contribute(layer libcnb.Layer) (libcnb.Layer, error) {
+	w.Logger.Header(color.BlueString("Webapp SBOM"))
+
+	libraries, err := w.Libraries()
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to inventory webapp libraries\n%w", err)
+	}
+	w.Logger.Bodyf("Found %d libraries in WEB-INF/lib", len(libraries))
+
+	var (
+		artifacts  []webappSyftArtifact
+		components []CycloneDXComponent
+	)
+	for _, l := range libraries {
+		a := webappSyftArtifact{
+			SyftArtifact: sbom.SyftArtifact{
+				Name:      l.Name,
+				Version:   l.Version,
+				Type:      "java-archive",
+				FoundBy:   "apache-tomcat",
+				Locations: []sbom.SyftLocation{{Path: l.Path}},
+				Language:  "java",
+				PURL:      l.PURL(),
+			},
+			MetadataType: "WebappMetadata",
+			Metadata:     map[string]string{"contextPath": l.ContextPath},
+		}
+		if a.ID, err = a.Hash(); err != nil {
+			return libcnb.Layer{}, fmt.Errorf("unable to generate hash\n%w", err)
+		}
+		artifacts = append(artifacts, a)
+
+		components = append(components, CycloneDXComponent{
+			BOMRef:  a.ID,
+			Type:    "library",
+			Group:   l.GroupID,
+			Name:    l.Name,
+			Version: l.Version,
+			PURL:    l.PURL(),
+			Properties: []CycloneDXProperty{
+				{Name: "paketo:tomcat:context-path", Value: l.ContextPath},
+				{Name: "paketo:tomcat:location", Value: l.Path},
+			},
+		})
+	}
+
+	d := sbom.NewSyftDependency(w.ApplicationPath, nil)
+	syft := webappSyftDependency{Artifacts: artifacts, Source: d.Source, Descriptor: d.Descriptor, Schema: d.Schema}
+	if syft.Artifacts == nil {
+		syft.Artifacts = []webappSyftArtifact{}
+	}
+
+	output, err := json.Marshal(&syft)
+	if err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to marshal to JSON\n%w", err)
+	}
+	file := layer.SBOMPath(libcnb.SyftJSON)
+	w.Logger.Debugf("Writing Syft SBOM at %s", file)
+	if err := os.WriteFile(file, output, 0644); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write SBOM to %s\n%w", file, err)
+	}
+
+	file = layer.SBOMPath(libcnb.CycloneDXJSON)
+	w.Logger.Debugf("Writing CycloneDX SBOM at %s", file)
+	if err := NewCycloneDXBOM("webapps", components).WriteTo(file); err != nil {
+		return libcnb.Layer{}, fmt.Errorf("unable to write SBOM\n%w", err)
+	}
+
+	layer.LayerTypes = libcnb.LayerTypes{Launch: true}
+	return layer, nil
+}
+
+// Libraries returns the JARs of every webapp, ordered by context path and location.
+func (w WebappSBOM) Libraries() ([]WebappLibrary, error) {
+	webapps := map[string]string{}
+
+	if w.WarFilesExist {
+		entries, err := os.ReadDir(w.ApplicationPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read directory %s\n%w", w.ApplicationPath, err)
+		}
+
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(w.ApplicationPath, e.Name(), "WEB-INF")); err == nil {
+				webapps[filepath.Join(w.ApplicationPath, e.Name())] = ContextPathFromName(e.Name())
+			}
+		}
+	} else {
+		webapps[w.ApplicationPath] = ContextPathFromName(w.ContextPath)
+	}
+
+	var libraries []WebappLibrary
+	for dir, contextPath := range webapps {
+		jars, err := filepath.Glob(filepath.Join(dir, "WEB-INF", "lib", "*.jar"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to list jars in %s\n%w", dir, err)
+		}
+
+		for _, jar := range jars {
+			l, err := w.library(jar)
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range l {
+				l[i].ContextPath = contextPath
+			}
+			libraries = append(libraries, l...)
+		}
+	}
+
+	sort.Slice(libraries, func(i, j int) bool {
+		if libraries[i].ContextPath != libraries[j].ContextPath {
+			return libraries[i].ContextPath < libraries[j].ContextPath
+		}
+		if libraries[i].Path != libraries[j].Path {
+			return libraries[i].Path < libraries[j].Path
+		}
+		return libraries[i].Name < libraries[j].Name
+	})
+
+	return libraries, nil
+}
+
+func (w WebappSBOM) library(jar string) ([]WebappLibrary, error) {
+	z, err := zip.OpenReader(jar)
+	if err != nil {
+		w.Logger.Bodyf("Skipping %s, unable to open as JAR: %s", jar, err)
+		return nil, nil
+	}
+	defer z.Close()
+
+	var libraries []WebappLibrary
+	for _, f := range z.File {
+		if !strings.HasPrefix(f.Name, "META-INF/maven/") || !strings.HasSuffix(f.Name, "/pom.properties") {
+			continue
+		}
+
+		in, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("unable to open %s in %s\n%w", f.Name, jar, err)
+		}
+
+		p, err := properties.LoadReader(in, properties.UTF8)
+		in.Close()
+		if err != nil {
+			w.Logger.Debugf("Unable to parse %s in %s: %s", f.Name, jar, err)
+			continue
+		}
+
+		l := WebappLibrary{
+			ArtifactID: p.GetString("artifactId", ""),
+			GroupID:    p.GetString("groupId", ""),
+			Path:       jar,
+			Version:    p.GetString("version", ""),
+		}
+		l.Name = l.ArtifactID
+		if l.Name != "" {
+			libraries = append(libraries, l)
+		}
+	}
+
+	if len(libraries) > 0 {
+		return libraries, nil
+	}
+
+	l := WebappLibrary{
+		Name: strings.TrimSuffix(filepath.Base(jar), ".jar"),
+		Path: jar,
+	}
+
+	if m, err := libjvm.NewManifestFromJAR(jar); err != nil {
+		w.Logger.Debugf("Unable to read manifest of %s: %s", jar, err)
+	} else {
+		for _, k := range []string{"Implementation-Title", "Bundle-SymbolicName", "Automatic-Module-Name"} {
+			if s, ok := m.Get(k); ok && s != "" {
+				l.Name = strings.TrimSpace(strings.SplitN(s, ";", 2)[0])
+				break
+			}
+		}
+		for _, k := range []string{"Implementation-Version", "Bundle-Version", "Specification-Version"} {
+			if s, ok := m.Get(k); ok && s != "" {
+				l.Version = strings.TrimSpace(s)
+				break
+			}
+		}
+	}
+
+	return []WebappLibrary{l}, nil
+}
+
+func (WebappSBOM) Name() string {
+	return "webapp-sbom"
+}
+
+// ContextPathFromName converts a Tomcat webapp name such as ROOT or alpha#bravo into a context path.
+func ContextPathFromName(name string) string {
+	if name == "" || name == "ROOT" {
+		return "/"
+	}
+	return "/" + strings.ReplaceAll(name, "#", "/")
+}
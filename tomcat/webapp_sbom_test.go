@@ -0,0 +1,135 @@
+/*
+ * Copyright 2018-2020 the original author or authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tomcat_test
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buildpacks/libcnb"
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+
+	"github.com/paketo-buildpacks/apache-tomcat/v8/tomcat"
+)
+
+func testWebappSBOM(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		ctx libcnb.BuildContext
+	)
+
+	writeJar := func(path string, entries map[string]string) {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		out, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer out.Close()
+
+		z := zip.NewWriter(out)
+		for name, content := range entries {
+			w, err := z.Create(name)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = w.Write([]byte(content))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(z.Close()).To(Succeed())
+	}
+
+	it.Before(func() {
+		var err error
+
+		ctx.Application.Path, err = os.MkdirTemp("", "webapp-sbom-application")
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx.Layers.Path, err = os.MkdirTemp("", "webapp-sbom-layers")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	it.After(func() {
+		Expect(os.RemoveAll(ctx.Application.Path)).To(Succeed())
+		Expect(os.RemoveAll(ctx.Layers.Path)).To(Succeed())
+	})
+
+	it("inventories the application at the context path", func() {
+		writeJar(filepath.Join(ctx.Application.Path, "WEB-INF", "lib", "alpha-1.0.0.jar"), map[string]string{
+			"META-INF/maven/com.example/alpha/pom.properties": "groupId=com.example\nartifactId=alpha\nversion=1.0.0\n",
+		})
+		writeJar(filepath.Join(ctx.Application.Path, "WEB-INF", "lib", "bravo.jar"), map[string]string{
+			"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nImplementation-Title: bravo\nImplementation-Version: 2.0.0\n",
+		})
+
+		w := tomcat.NewWebappSBOM(ctx.Application.Path, "alpha#bravo", false)
+
+		Expect(w.Libraries()).To(Equal([]tomcat.WebappLibrary{
+			{
+				ArtifactID:  "alpha",
+				ContextPath: "/alpha/bravo",
+				GroupID:     "com.example",
+				Name:        "alpha",
+				Path:        filepath.Join(ctx.Application.Path, "WEB-INF", "lib", "alpha-1.0.0.jar"),
+				Version:     "1.0.0",
+			},
+			{
+				ContextPath: "/alpha/bravo",
+				Name:        "bravo",
+				Path:        filepath.Join(ctx.Application.Path, "WEB-INF", "lib", "bravo.jar"),
+				Version:     "2.0.0",
+			},
+		}))
+
+		layer, err := ctx.Layers.Layer("test-layer")
+		Expect(err).NotTo(HaveOccurred())
+
+		layer, err = w.Contribute(layer)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(layer.Launch).To(BeTrue())
+		Expect(layer.SBOMPath(libcnb.SyftJSON)).To(BeARegularFile())
+
+		b, err := os.ReadFile(layer.SBOMPath(libcnb.CycloneDXJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		var bom tomcat.CycloneDXBOM
+		Expect(json.Unmarshal(b, &bom)).To(Succeed())
+		Expect(bom.Components).To(HaveLen(2))
+		Expect(bom.Components[0].PURL).To(Equal("pkg:maven/com.example/alpha@1.0.0"))
+		Expect(bom.Components[0].Properties).To(ContainElement(tomcat.CycloneDXProperty{Name: "paketo:tomcat:context-path", Value: "/alpha/bravo"}))
+	})
+
+	it("inventories each exploded war file", func() {
+		writeJar(filepath.Join(ctx.Application.Path, "api", "WEB-INF", "lib", "alpha.jar"), map[string]string{
+			"META-INF/maven/com.example/alpha/pom.properties": "groupId=com.example\nartifactId=alpha\nversion=1.0.0\n",
+		})
+		writeJar(filepath.Join(ctx.Application.Path, "ROOT", "WEB-INF", "lib", "alpha.jar"), map[string]string{
+			"META-INF/maven/com.example/alpha/pom.properties": "groupId=com.example\nartifactId=alpha\nversion=1.1.0\n",
+		})
+		Expect(os.MkdirAll(filepath.Join(ctx.Application.Path, "not-a-webapp"), 0755)).To(Succeed())
+
+		libraries, err := tomcat.NewWebappSBOM(ctx.Application.Path, "ROOT", true).Libraries()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(libraries).To(HaveLen(2))
+		Expect(libraries[0].ContextPath).To(Equal("/"))
+		Expect(libraries[0].Version).To(Equal("1.1.0"))
+		Expect(libraries[1].ContextPath).To(Equal("/api"))
+		Expect(libraries[1].Version).To(Equal("1.0.0"))
+	})
+}